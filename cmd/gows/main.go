@@ -0,0 +1,162 @@
+// Command gows serves live and historical cryptocurrency prices over REST
+// and a client-facing websocket, pulling from a configurable upstream
+// exchange with a CoinGecko fallback for stale feeds.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/dchoweller/gows/internal/config"
+	"github.com/dchoweller/gows/internal/exchange"
+	"github.com/dchoweller/gows/internal/httpapi"
+	"github.com/dchoweller/gows/internal/store"
+)
+
+// initializeStore fetches instrument and currency metadata for every
+// configured symbol from ex and seeds st and symbolInfo with it.
+func initializeStore(ex exchange.Exchange, symbols []string, st store.Store, symbolInfo map[string]*exchange.SymbolInfo) error {
+	for _, symbol := range symbols {
+		pair := exchange.ParsePair(symbol)
+		symInfo, err := ex.GetSymbol(pair)
+		if err != nil {
+			log.Printf("GetSymbol %v failed: %v", symbol, err)
+			return err
+		}
+		symbolInfo[symbol] = symInfo
+
+		currencyInfo, err := ex.GetCurrency(exchange.Currency(symInfo.BaseCurrency))
+		if err != nil {
+			log.Printf("GetCurrency %v failed: %v", symbol, err)
+			return err
+		}
+		st.Seed(symbol, store.CurrencyInfo{
+			ID:          symInfo.BaseCurrency,
+			FullName:    currencyInfo.FullName,
+			FeeCurrency: symInfo.FeeCurrency,
+			Exchange:    ex.Name(),
+		})
+	}
+	return nil
+}
+
+func main() {
+	conf := config.Load()
+	flag.Parse() // command line flags (currently unused)
+	log.SetFlags(0)
+
+	ex, err := exchange.New(conf.Exchange)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	st := store.New(conf.Symbols)
+	symbolInfo := make(map[string]*exchange.SymbolInfo)
+	if err := initializeStore(ex, conf.Symbols, st, symbolInfo); err != nil {
+		log.Fatal(err)
+	}
+
+	var klines *exchange.KlineStore
+	if _, ok := ex.(exchange.KlineProvider); ok {
+		klines = exchange.NewKlineStore(exchange.DefaultKlineCapacity)
+	}
+	server := httpapi.NewServer(st, conf.Symbols, ex, klines, symbolInfo)
+
+	// Subscribe to ticker updates for every configured symbol. Each
+	// subscription gets its own channel; a single goroutine fans updates
+	// from all of them into the store, and pushes them to /ws subscribers.
+	pairs := make(map[string]exchange.CurrencyPair, len(conf.Symbols))
+	tickerCh := make(chan exchange.Ticker)
+	for _, symbol := range conf.Symbols {
+		pair := exchange.ParsePair(symbol)
+		pairs[symbol] = pair
+		if err := ex.SubscribeTicker(pair, tickerCh); err != nil {
+			log.Println("SubscribeTicker:", err)
+			return
+		}
+	}
+
+	publish := func(t exchange.Ticker, source string) {
+		st.Update(store.TickerUpdate{
+			Symbol: t.Symbol,
+			Ask:    t.Ask,
+			Bid:    t.Bid,
+			Last:   t.Last,
+			Open:   t.Open,
+			Low:    t.Low,
+			High:   t.High,
+			Source: source,
+		})
+		info, _ := st.Get(t.Symbol)
+		server.Publish(t.Symbol, info)
+	}
+
+	// Watch for symbols whose primary feed has gone stale and fall back to
+	// CoinGecko polling for them until the primary feed recovers.
+	priceAgg := exchange.NewPriceAggregator(exchange.NewCoingeckoSource(), pairs, publish)
+	priceAgg.Run(exchange.DefaultStalenessWindow, exchange.DefaultFallbackPoll)
+
+	go func() {
+		for t := range tickerCh {
+			priceAgg.NoteUpdate(t.Symbol)
+			publish(t, ex.Name())
+		}
+	}()
+
+	// If the active exchange can serve historical and live candles, seed
+	// the cache for every symbol at every supported period and keep it
+	// current.
+	if klineProvider, ok := ex.(exchange.KlineProvider); ok {
+		candleCh := make(chan exchange.Kline)
+		for _, symbol := range conf.Symbols {
+			pair := pairs[symbol]
+			for _, period := range exchange.AllKlinePeriods {
+				seed, err := klineProvider.GetKlines(pair, period, exchange.DefaultKlineCapacity)
+				if err != nil {
+					log.Printf("GetKlines %v %v failed: %v", symbol, period, err)
+				} else {
+					klines.Seed(symbol, period, seed)
+				}
+				if err := klineProvider.SubscribeCandles(pair, period, candleCh); err != nil {
+					log.Printf("SubscribeCandles %v %v failed: %v", symbol, period, err)
+				}
+			}
+		}
+		go func() {
+			for k := range candleCh {
+				klines.Add(k.Symbol, k.Period, k)
+			}
+		}()
+	}
+
+	// Listen for API requests
+	httpServer := &http.Server{Addr: conf.Hostname + ":" + conf.Port, Handler: server.Router()}
+	log.Printf("Server listening on host %v, port %v...", conf.Hostname, conf.Port)
+
+	go func() {
+		log.Fatal(httpServer.ListenAndServe())
+	}()
+
+	// Allow server to be interrupted
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+
+	// Wait for interrupt
+	<-interrupt
+
+	// Tear down the upstream exchange connection, if it holds one, so its
+	// reconnect/ping goroutines stop cleanly alongside the server.
+	if closer, ok := ex.(exchange.Closer); ok {
+		closer.Close()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// Wait with timeout for server to close connection
+	httpServer.Shutdown(ctx)
+}
@@ -0,0 +1,32 @@
+// Package config loads this service's environment-based configuration.
+package config
+
+import "github.com/kelseyhightower/envconfig"
+
+// Config is the application configuration, populated from environment
+// variables prefixed DCHOWELLER_CRYPTO_.
+type Config struct {
+	Hostname string   `default:"localhost"`
+	Port     string   `default:"8080"`
+	Symbols  []string `required:"true"`
+	Exchange string   `default:"hitbtc"`
+}
+
+// Load reads Config from the environment.
+// Default hostname: localhost
+// Default port: 8080
+// Default symbols to include: BTCUSD, ETHBTC
+// Default exchange: hitbtc
+func Load() Config {
+	var c Config
+	err := envconfig.Process("dchoweller_crypto", &c)
+	if err != nil { // If error reading environment, use default values
+		c.Hostname = "localhost"
+		c.Port = "8080"
+		c.Symbols = []string{"BTCUSD", "ETHBTC"}
+	}
+	if c.Exchange == "" {
+		c.Exchange = "hitbtc"
+	}
+	return c
+}
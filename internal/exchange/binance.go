@@ -0,0 +1,165 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+func init() {
+	Register("binance", newBinanceExchange)
+}
+
+const (
+	binanceStreamAddr   = "stream.binance.com:9443"
+	binanceDefaultQuote = "USDT"
+)
+
+// binanceRESTBase is a var, rather than a const, so tests can point it at a
+// local httptest server instead of the real Binance API.
+var binanceRESTBase = "https://api.binance.com"
+
+// binanceExchange is the Exchange implementation backed by Binance's public
+// REST and websocket stream APIs (https://binance-docs.github.io/apidocs/).
+// Unlike HitBTC, Binance exposes one websocket stream per symbol, so each
+// SubscribeTicker call owns its own connection and goroutine rather than
+// sharing a single multiplexed one.
+type binanceExchange struct{}
+
+func newBinanceExchange() Exchange {
+	return &binanceExchange{}
+}
+
+func (b *binanceExchange) Name() string {
+	return "binance"
+}
+
+// binanceSymbol normalizes a pair to Binance's concatenated form. HitBTC's
+// "USD" quote doesn't exist on Binance, which trades against the USDT
+// stablecoin instead, so substitute it.
+func binanceSymbol(pair CurrencyPair) string {
+	quote := pair.Quote
+	if quote == "USD" {
+		quote = binanceDefaultQuote
+	}
+	return string(pair.Base) + string(quote)
+}
+
+type binanceExchangeInfoResponse struct {
+	Symbols []struct {
+		Symbol     string `json:"symbol"`
+		BaseAsset  string `json:"baseAsset"`
+		QuoteAsset string `json:"quoteAsset"`
+		Filters    []struct {
+			FilterType string `json:"filterType"`
+			TickSize   string `json:"tickSize"`
+			StepSize   string `json:"stepSize"`
+		} `json:"filters"`
+	} `json:"symbols"`
+}
+
+// GetSymbol fetches instrument metadata from Binance's /exchangeInfo endpoint.
+func (b *binanceExchange) GetSymbol(pair CurrencyPair) (*SymbolInfo, error) {
+	symbol := binanceSymbol(pair)
+	u := fmt.Sprintf("%s/api/v3/exchangeInfo?symbol=%s", binanceRESTBase, url.QueryEscape(symbol))
+	resp, err := http.Get(u)
+	if err != nil {
+		log.Println("binance: failed to fetch exchangeInfo", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var ei binanceExchangeInfoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ei); err != nil {
+		log.Println("binance: failed to decode exchangeInfo", err)
+		return nil, err
+	}
+	if len(ei.Symbols) == 0 {
+		return nil, fmt.Errorf("binance: unknown symbol %v", symbol)
+	}
+	s := ei.Symbols[0]
+
+	info := &SymbolInfo{
+		ID:            s.Symbol,
+		BaseCurrency:  s.BaseAsset,
+		QuoteCurrency: s.QuoteAsset,
+	}
+	for _, f := range s.Filters {
+		switch f.FilterType {
+		case "PRICE_FILTER":
+			info.TickSize = f.TickSize
+		case "LOT_SIZE":
+			info.QuantityIncrement = f.StepSize
+		}
+	}
+	return info, nil
+}
+
+// GetCurrency returns minimal currency metadata: Binance's public API has no
+// equivalent of HitBTC's getCurrency, so this just echoes the asset ticker.
+func (b *binanceExchange) GetCurrency(cur Currency) (*CurrencyInfo, error) {
+	return &CurrencyInfo{
+		ID:       string(cur),
+		FullName: string(cur),
+		Crypto:   true,
+	}, nil
+}
+
+// binanceTickerStream is a single frame of Binance's 24hr ticker stream
+// (https://binance-docs.github.io/apidocs/spot/en/#individual-symbol-ticker-streams).
+type binanceTickerStream struct {
+	Symbol      string `json:"s"`
+	LastPrice   string `json:"c"`
+	OpenPrice   string `json:"o"`
+	HighPrice   string `json:"h"`
+	LowPrice    string `json:"l"`
+	Volume      string `json:"v"`
+	QuoteVolume string `json:"q"`
+	BestBid     string `json:"b"`
+	BestAsk     string `json:"a"`
+}
+
+// SubscribeTicker opens a dedicated stream connection for pair and forwards
+// every ticker frame to ch until the connection errors.
+func (b *binanceExchange) SubscribeTicker(pair CurrencyPair, ch chan<- Ticker) error {
+	symbol := strings.ToLower(binanceSymbol(pair))
+	u := url.URL{Scheme: "wss", Host: binanceStreamAddr, Path: "/ws/" + symbol + "@ticker"}
+	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	if err != nil {
+		log.Println("binance: dial failed", err)
+		return err
+	}
+
+	go func() {
+		defer conn.Close()
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				log.Println("binance: read:", err)
+				return
+			}
+			var frame binanceTickerStream
+			if err := json.Unmarshal(message, &frame); err != nil {
+				log.Println("binance: failed to unmarshal ticker frame", err)
+				continue
+			}
+			ch <- Ticker{
+				Symbol:      pair.Symbol(),
+				Ask:         frame.BestAsk,
+				Bid:         frame.BestBid,
+				Last:        frame.LastPrice,
+				Open:        frame.OpenPrice,
+				Low:         frame.LowPrice,
+				High:        frame.HighPrice,
+				Volume:      frame.Volume,
+				VolumeQuote: frame.QuoteVolume,
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,78 @@
+package exchange
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBinanceSymbol(t *testing.T) {
+	cases := []struct {
+		pair CurrencyPair
+		want string
+	}{
+		{CurrencyPair{Base: "BTC", Quote: "USD"}, "BTCUSDT"},
+		{CurrencyPair{Base: "ETH", Quote: "BTC"}, "ETHBTC"},
+	}
+	for _, c := range cases {
+		if got := binanceSymbol(c.pair); got != c.want {
+			t.Errorf("binanceSymbol(%+v) = %q, want %q", c.pair, got, c.want)
+		}
+	}
+}
+
+func TestBinanceGetSymbol(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"symbols":[{"symbol":"BTCUSDT","baseAsset":"BTC","quoteAsset":"USDT","filters":[
+			{"filterType":"PRICE_FILTER","tickSize":"0.01"},
+			{"filterType":"LOT_SIZE","stepSize":"0.0001"}
+		]}]}`))
+	}))
+	defer ts.Close()
+
+	old := binanceRESTBase
+	binanceRESTBase = ts.URL
+	defer func() { binanceRESTBase = old }()
+
+	b := newBinanceExchange()
+	info, err := b.GetSymbol(CurrencyPair{Base: "BTC", Quote: "USD"})
+	if err != nil {
+		t.Fatalf("GetSymbol: %v", err)
+	}
+	if info.ID != "BTCUSDT" || info.TickSize != "0.01" || info.QuantityIncrement != "0.0001" {
+		t.Errorf("GetSymbol = %+v, want filters mapped to TickSize/QuantityIncrement", info)
+	}
+}
+
+func TestBinanceGetSymbolUnknown(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"symbols":[]}`))
+	}))
+	defer ts.Close()
+
+	old := binanceRESTBase
+	binanceRESTBase = ts.URL
+	defer func() { binanceRESTBase = old }()
+
+	b := newBinanceExchange()
+	if _, err := b.GetSymbol(CurrencyPair{Base: "NOPE", Quote: "USD"}); err == nil {
+		t.Error("expected an error for a symbol Binance doesn't know about")
+	}
+}
+
+func TestBinanceGetCurrency(t *testing.T) {
+	b := newBinanceExchange()
+	info, err := b.GetCurrency("BTC")
+	if err != nil {
+		t.Fatalf("GetCurrency: %v", err)
+	}
+	if info.ID != "BTC" || !info.Crypto {
+		t.Errorf("GetCurrency(BTC) = %+v, want ID=BTC Crypto=true", info)
+	}
+}
+
+func TestBinanceName(t *testing.T) {
+	if got := newBinanceExchange().Name(); got != "binance" {
+		t.Errorf("Name() = %q, want %q", got, "binance")
+	}
+}
@@ -0,0 +1,74 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// coingeckoBaseURL is a var, rather than a const, so tests can point it at a
+// local httptest server instead of the real CoinGecko API.
+var coingeckoBaseURL = "https://api.coingecko.com/api/v3"
+
+// coingeckoIDs maps the base currencies this service knows how to quote to
+// CoinGecko's internal coin ids, since its /simple/price endpoint addresses
+// coins by id rather than ticker symbol.
+var coingeckoIDs = map[Currency]string{
+	"BTC": "bitcoin",
+	"ETH": "ethereum",
+}
+
+// coingeckoSource is a REST PriceSource used as a fallback when a symbol's
+// primary exchange feed goes stale, modeled on status-go's CoinGecko
+// fallback pattern.
+type coingeckoSource struct{}
+
+// NewCoingeckoSource builds the CoinGecko fallback PriceSource.
+func NewCoingeckoSource() PriceSource {
+	return &coingeckoSource{}
+}
+
+func (c *coingeckoSource) Name() string {
+	return "coingecko"
+}
+
+// FetchPrice polls /simple/price for pair's base currency, quoted in pair's
+// quote currency (defaulting to USD when the pair has no quote, e.g. a
+// symbol parsePair couldn't split).
+func (c *coingeckoSource) FetchPrice(pair CurrencyPair) (Ticker, error) {
+	id, ok := coingeckoIDs[pair.Base]
+	if !ok {
+		return Ticker{}, fmt.Errorf("coingecko: no id mapping for %v", pair.Base)
+	}
+	vsCurrency := strings.ToLower(string(pair.Quote))
+	if vsCurrency == "" {
+		vsCurrency = "usd"
+	}
+
+	u := fmt.Sprintf("%s/simple/price?ids=%s&vs_currencies=%s&include_24hr_high=true&include_24hr_low=true",
+		coingeckoBaseURL, url.QueryEscape(id), url.QueryEscape(vsCurrency))
+	resp, err := http.Get(u)
+	if err != nil {
+		return Ticker{}, err
+	}
+	defer resp.Body.Close()
+
+	var payload map[string]map[string]float64
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return Ticker{}, err
+	}
+	coin, ok := payload[id]
+	if !ok {
+		return Ticker{}, fmt.Errorf("coingecko: no data for %v", id)
+	}
+
+	return Ticker{
+		Symbol: pair.Symbol(),
+		Last:   strconv.FormatFloat(coin[vsCurrency], 'f', -1, 64),
+		High:   strconv.FormatFloat(coin[vsCurrency+"_24h_high"], 'f', -1, 64),
+		Low:    strconv.FormatFloat(coin[vsCurrency+"_24h_low"], 'f', -1, 64),
+	}, nil
+}
@@ -0,0 +1,59 @@
+package exchange
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCoingeckoFetchPrice(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"bitcoin":{"usd":50000,"usd_24h_high":51000,"usd_24h_low":49000}}`))
+	}))
+	defer ts.Close()
+
+	old := coingeckoBaseURL
+	coingeckoBaseURL = ts.URL
+	defer func() { coingeckoBaseURL = old }()
+
+	src := NewCoingeckoSource()
+	ticker, err := src.FetchPrice(CurrencyPair{Base: "BTC", Quote: "USD"})
+	if err != nil {
+		t.Fatalf("FetchPrice: %v", err)
+	}
+	if ticker.Last != "50000" || ticker.High != "51000" || ticker.Low != "49000" {
+		t.Errorf("FetchPrice = %+v, want Last/High/Low from the simple/price response", ticker)
+	}
+	if ticker.Symbol != "BTCUSD" {
+		t.Errorf("Symbol = %q, want %q", ticker.Symbol, "BTCUSD")
+	}
+}
+
+func TestCoingeckoFetchPriceUnknownBase(t *testing.T) {
+	src := NewCoingeckoSource()
+	if _, err := src.FetchPrice(CurrencyPair{Base: "NOPE", Quote: "USD"}); err == nil {
+		t.Error("expected an error for a base currency with no CoinGecko id mapping")
+	}
+}
+
+func TestCoingeckoFetchPriceNoData(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	old := coingeckoBaseURL
+	coingeckoBaseURL = ts.URL
+	defer func() { coingeckoBaseURL = old }()
+
+	src := NewCoingeckoSource()
+	if _, err := src.FetchPrice(CurrencyPair{Base: "BTC", Quote: "USD"}); err == nil {
+		t.Error("expected an error when the response has no data for the requested coin")
+	}
+}
+
+func TestCoingeckoName(t *testing.T) {
+	if got := NewCoingeckoSource().Name(); got != "coingecko" {
+		t.Errorf("Name() = %q, want %q", got, "coingecko")
+	}
+}
@@ -0,0 +1,181 @@
+// Package exchange abstracts over the price venues this service can pull
+// from: an Exchange interface implemented per-venue (hitbtc.go, binance.go),
+// plus optional capability interfaces (KlineProvider, Closer,
+// DynamicSubscriber) that a venue implements only if it supports them.
+package exchange
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Currency is a single asset ticker, e.g. "BTC" or "USD".
+type Currency string
+
+// CurrencyPair identifies a tradeable pair, along with the tick sizes it is
+// quoted at on the active exchange.
+type CurrencyPair struct {
+	Base           Currency
+	Quote          Currency
+	AmountTickSize int
+	PriceTickSize  int
+}
+
+// Symbol returns the pair in HitBTC's native concatenated form, e.g.
+// "BTCUSD". Exchange implementations translate this to their own
+// venue-specific form (Binance's "BTCUSDT", Coinbase's "BTC-USD", ...).
+func (p CurrencyPair) Symbol() string {
+	return string(p.Base) + string(p.Quote)
+}
+
+// SymbolInfo is exchange metadata about a tradeable pair.
+type SymbolInfo struct {
+	ID                   string
+	BaseCurrency         string
+	QuoteCurrency        string
+	QuantityIncrement    string
+	TickSize             string
+	TakeLiquidityRate    string
+	ProvideLiquidityRate string
+	FeeCurrency          string
+}
+
+// CurrencyInfo is exchange metadata about a single currency/asset.
+type CurrencyInfo struct {
+	ID       string
+	FullName string
+	Crypto   bool
+}
+
+// Ticker is a single price update for a subscribed pair.
+type Ticker struct {
+	Symbol      string
+	Ask         string
+	Bid         string
+	Last        string
+	Open        string
+	Low         string
+	High        string
+	Volume      string
+	VolumeQuote string
+	Timestamp   time.Time
+}
+
+// Exchange is the interface every supported price venue implements. It is
+// deliberately narrow: just enough to initialize and stream the currency
+// data this service serves.
+type Exchange interface {
+	// Name identifies the exchange, surfaced in REST responses.
+	Name() string
+	// GetSymbol fetches instrument metadata for pair.
+	GetSymbol(pair CurrencyPair) (*SymbolInfo, error)
+	// GetCurrency fetches metadata for a single currency.
+	GetCurrency(cur Currency) (*CurrencyInfo, error)
+	// SubscribeTicker streams ticker updates for pair onto ch until the
+	// exchange connection is closed or the subscription fails.
+	SubscribeTicker(pair CurrencyPair, ch chan<- Ticker) error
+}
+
+// factories is the registry of exchanges buildable by name, keyed by the
+// value accepted by the DCHOWELLER_CRYPTO_EXCHANGE environment variable.
+var factories = map[string]func() Exchange{}
+
+// Register adds name to the registry. Exchange implementations call this
+// from an init() in their own file.
+func Register(name string, factory func() Exchange) {
+	factories[name] = factory
+}
+
+// New builds the exchange registered under name.
+func New(name string) (Exchange, error) {
+	factory, ok := factories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown exchange %q", name)
+	}
+	return factory(), nil
+}
+
+// KlinePeriod identifies a candlestick granularity, using HitBTC's period
+// vocabulary since it's the same one goex and most exchange libraries use.
+type KlinePeriod string
+
+// Supported kline periods.
+const (
+	KlineM1  KlinePeriod = "M1"
+	KlineM5  KlinePeriod = "M5"
+	KlineM15 KlinePeriod = "M15"
+	KlineM30 KlinePeriod = "M30"
+	KlineH1  KlinePeriod = "H1"
+	KlineH4  KlinePeriod = "H4"
+	KlineD1  KlinePeriod = "D1"
+)
+
+// AllKlinePeriods lists every period a KlineProvider is expected to serve,
+// ascending by granularity. cmd/gows/main.go seeds and subscribes each of
+// these per symbol so /klines can serve any of them from cache.
+var AllKlinePeriods = []KlinePeriod{KlineM1, KlineM5, KlineM15, KlineM30, KlineH1, KlineH4, KlineD1}
+
+// Kline is a single OHLCV candlestick, modeled on goex's GetKlineRecords
+// shape so consumers get the same data model they'd expect from other
+// exchange libraries.
+type Kline struct {
+	Symbol      string      `json:"symbol"`
+	Period      KlinePeriod `json:"period"`
+	Timestamp   time.Time   `json:"timestamp"`
+	Open        string      `json:"open"`
+	Close       string      `json:"close"`
+	Low         string      `json:"low"`
+	High        string      `json:"high"`
+	Volume      string      `json:"volume"`
+	VolumeQuote string      `json:"volumeQuote"`
+}
+
+// KlineProvider is implemented by exchanges that can serve historical and
+// live candlestick data. Not every Exchange needs to; the /klines endpoint
+// degrades to an error for exchanges that don't.
+type KlineProvider interface {
+	// GetKlines fetches up to limit of the most recent candles for pair at
+	// the given period.
+	GetKlines(pair CurrencyPair, period KlinePeriod, limit int) ([]Kline, error)
+	// SubscribeCandles streams live candle updates for pair onto ch.
+	SubscribeCandles(pair CurrencyPair, period KlinePeriod, ch chan<- Kline) error
+}
+
+// Closer is implemented by exchanges that hold background resources (a
+// connection, reconnect/ping goroutines) that need tearing down on
+// shutdown.
+type Closer interface {
+	Close()
+}
+
+// DynamicSubscriber is implemented by exchanges that support subscribing to
+// ticker updates for symbols beyond the ones configured at startup, so a
+// client-driven endpoint (e.g. /ws) can add them at runtime. Subscribe takes
+// a channel, the same way SubscribeTicker does, since the caller has no
+// other way to receive the resulting updates. Unsubscribe takes that same
+// channel so the implementation can stop delivering to it specifically,
+// rather than to every caller ever subscribed to symbol.
+type DynamicSubscriber interface {
+	Subscribe(symbol string, ch chan<- Ticker) error
+	Unsubscribe(symbol string, ch chan<- Ticker)
+}
+
+// knownQuoteCurrencies lists quote currencies tried, longest first, when
+// splitting a plain "BASEQUOTE" symbol (the format this service's symbol
+// list has always used) into a CurrencyPair.
+var knownQuoteCurrencies = []string{"USDT", "USD", "BTC", "ETH", "EUR"}
+
+// ParsePair splits a concatenated symbol such as "BTCUSD" into its base and
+// quote currencies.
+func ParsePair(symbol string) CurrencyPair {
+	for _, quote := range knownQuoteCurrencies {
+		if strings.HasSuffix(symbol, quote) && len(symbol) > len(quote) {
+			return CurrencyPair{
+				Base:  Currency(strings.TrimSuffix(symbol, quote)),
+				Quote: Currency(quote),
+			}
+		}
+	}
+	return CurrencyPair{Base: Currency(symbol)}
+}
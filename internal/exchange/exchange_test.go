@@ -0,0 +1,30 @@
+package exchange
+
+import "testing"
+
+func TestParsePair(t *testing.T) {
+	cases := []struct {
+		symbol string
+		base   Currency
+		quote  Currency
+	}{
+		{"BTCUSD", "BTC", "USD"},
+		{"ETHBTC", "ETH", "BTC"},
+		{"BTCUSDT", "BTC", "USDT"},
+		{"ETHEUR", "ETH", "EUR"},
+		{"FOO", "FOO", ""},
+	}
+	for _, c := range cases {
+		got := ParsePair(c.symbol)
+		if got.Base != c.base || got.Quote != c.quote {
+			t.Errorf("ParsePair(%q) = %+v, want Base=%q Quote=%q", c.symbol, got, c.base, c.quote)
+		}
+	}
+}
+
+func TestCurrencyPairSymbol(t *testing.T) {
+	pair := CurrencyPair{Base: "BTC", Quote: "USD"}
+	if got := pair.Symbol(); got != "BTCUSD" {
+		t.Errorf("Symbol() = %q, want %q", got, "BTCUSD")
+	}
+}
@@ -0,0 +1,764 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"log"
+	"math/rand"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func init() {
+	Register("hitbtc", newHitbtcExchange)
+}
+
+// Address of the HitBTC websocket API to connect to.
+var addr = flag.String("addr", "api.hitbtc.com", "http service address")
+
+// strcuture retreived by ticker update websocket API (https://api.hitbtc.com/#subscribe-to-ticker)
+type tickerUpdate struct {
+	Jsonrpc string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  struct {
+		Ask         string    `json:"ask"`
+		Bid         string    `json:"bid"`
+		Last        string    `json:"last"`
+		Open        string    `json:"open"`
+		Low         string    `json:"low"`
+		High        string    `json:"high"`
+		Volume      string    `json:"volume"`
+		VolumeQuote string    `json:"volumeQuote"`
+		Timestamp   time.Time `json:"timestamp"`
+		Symbol      string    `json:"symbol"`
+	} `json:"params"`
+}
+
+// Parameter of request to ticket update websocket API (https://api.hitbtc.com/#subscribe-to-ticker)
+// or GetSymbol API (https://api.hitbtc.com/#get-symbols)
+type symbolParam struct {
+	Symbol string `json:"symbol"`
+}
+
+// Command to retrieve ticker update or get Symbol (Method: "getSymbol" or Method: "subscribeTicket")
+type getSymbolCommand struct {
+	Method string      `json:"method"`
+	Params symbolParam `json:"params"`
+	ID     int         `json:"id"`
+}
+
+// Response from GetSymbol API (https://api.hitbtc.com/#get-symbols)
+type getSymbolResponse struct {
+	Jsonrpc string `json:"jsonrpc"`
+	Result  struct {
+		ID                   string `json:"id"`
+		BaseCurrency         string `json:"baseCurrency"`
+		QuoteCurrency        string `json:"quoteCurrency"`
+		QuantityIncrement    string `json:"quantityIncrement"`
+		TickSize             string `json:"tickSize"`
+		TakeLiquidityRate    string `json:"takeLiquidityRate"`
+		ProvideLiquidityRate string `json:"provideLiquidityRate"`
+		FeeCurrency          string `json:"feeCurrency"`
+	} `json:"result"`
+	ID int `json:"id"`
+}
+
+// Command to subscribe to ticker (https://api.hitbtc.com/#subscribe-to-ticker)
+type subscribeTickerCommand struct {
+	Method string      `json:"method"`
+	Params symbolParam `json:"params"`
+	ID     int         `json:"id"`
+}
+
+// Parameter of Get Currency API (https://api.hitbtc.com/#get-currencies)
+type getCurrencyParam struct {
+	Currency string `json:"currency"`
+}
+
+// Get Currency request
+type getCurrencyCommand struct {
+	Method string           `json:"method"`
+	Params getCurrencyParam `json:"params"`
+	ID     int              `json:"id"`
+}
+
+// Get Currency response
+type getCurrencyResponse struct {
+	Jsonrpc string `json:"jsonrpc"`
+	Result  struct {
+		ID                  string `json:"id"`
+		FullName            string `json:"fullName"`
+		Crypto              bool   `json:"crypto"`
+		PayinEnabled        bool   `json:"payinEnabled"`
+		PayinPaymentID      bool   `json:"payinPaymentId"`
+		PayinConfirmations  int    `json:"payinConfirmations"`
+		PayoutEnabled       bool   `json:"payoutEnabled"`
+		PayoutIsPaymentID   bool   `json:"payoutIsPaymentId"`
+		TransferEnabled     bool   `json:"transferEnabled"`
+		Delisted            bool   `json:"delisted"`
+		PayoutFee           string `json:"payoutFee"`
+		PayoutMinimalAmount string `json:"payoutMinimalAmount"`
+		PrecisionPayout     int    `json:"precisionPayout"`
+		PrecisionTransfer   int    `json:"precisionTransfer"`
+	} `json:"result"`
+	ID int `json:"id"`
+}
+
+// Parameter of the getCandles/subscribeCandles APIs
+// (https://api.hitbtc.com/#candles, https://api.hitbtc.com/#subscribe-to-candles)
+type candlesParam struct {
+	Symbol string `json:"symbol"`
+	Period string `json:"period"`
+	Limit  int    `json:"limit"`
+}
+
+// Command to retrieve or subscribe to candles
+type candlesCommand struct {
+	Method string       `json:"method"`
+	Params candlesParam `json:"params"`
+	ID     int          `json:"id"`
+}
+
+// A single candle as returned by getCandles or pushed by subscribeCandles
+type hitbtcCandle struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Open        string    `json:"open"`
+	Close       string    `json:"close"`
+	Min         string    `json:"min"`
+	Max         string    `json:"max"`
+	Volume      string    `json:"volume"`
+	VolumeQuote string    `json:"volumeQuote"`
+}
+
+// Response from the getCandles API
+type getCandlesResponse struct {
+	Jsonrpc string         `json:"jsonrpc"`
+	Result  []hitbtcCandle `json:"result"`
+	ID      int            `json:"id"`
+}
+
+// snapshotCandles/updateCandles push, sent after subscribeCandles
+type candlesUpdate struct {
+	Method string `json:"method"`
+	Params struct {
+		Symbol string         `json:"symbol"`
+		Period string         `json:"period"`
+		Data   []hitbtcCandle `json:"data"`
+	} `json:"params"`
+}
+
+func hitbtcCandleToKline(symbol string, period KlinePeriod, c hitbtcCandle) Kline {
+	return Kline{
+		Symbol:      symbol,
+		Period:      period,
+		Timestamp:   c.Timestamp,
+		Open:        c.Open,
+		Close:       c.Close,
+		Low:         c.Min,
+		High:        c.Max,
+		Volume:      c.Volume,
+		VolumeQuote: c.VolumeQuote,
+	}
+}
+
+// candleSub is a single tracked (symbol, period) candle subscription,
+// replayed by resubscribeAll after a reconnect.
+type candleSub struct {
+	symbol string
+	period KlinePeriod
+}
+
+// candleKey identifies candleChans/candleSubs entries for one symbol at one
+// period, since a symbol can be subscribed at several periods at once and
+// dispatchCandles must only forward an update to channels subscribed at its
+// period, not every channel ever registered for the symbol.
+func candleKey(symbol string, period KlinePeriod) string {
+	return symbol + "|" + string(period)
+}
+
+// Backoff bounds for reconnecting to the upstream websocket, and the
+// liveness-check period/deadline for the ping/pong keepalive.
+const (
+	minReconnectBackoff = 100 * time.Millisecond
+	maxReconnectBackoff = 30 * time.Second
+	pingPeriod          = 30 * time.Second
+	pongWait            = 90 * time.Second
+	pingWriteWait       = 10 * time.Second
+)
+
+// hitbtcExchange is the Exchange implementation backed by HitBTC's JSON-RPC
+// websocket API (https://api.hitbtc.com). Unlike most venues, HitBTC
+// multiplexes every request/response and every subscription's pushed
+// updates over a single connection, so this type owns that connection and
+// demultiplexes incoming frames: pushes (identified by a "method" field) are
+// forwarded to the channels registered by SubscribeTicker/SubscribeCandles,
+// and plain request/response replies (identified by a matching "id") are
+// delivered to whichever call() is waiting on them. readLoop is the
+// connection's only reader, so GetSymbol/GetCurrency/GetKlines never touch
+// conn.ReadMessage directly. It also supervises the connection: on any read
+// error it redials with jittered exponential backoff and replays every
+// tracked subscription before resuming.
+type hitbtcExchange struct {
+	mu          sync.Mutex
+	conn        *websocket.Conn
+	tickerChans map[string][]chan<- Ticker
+	candleChans map[string][]chan<- Kline
+	tickerSubs  map[string]struct{}
+	candleSubs  map[string]candleSub
+	readLoopOn  bool
+	pending     map[int]chan []byte
+	nextReqID   uint64
+
+	// writeMu serializes every conn.WriteMessage call. gorilla/websocket
+	// allows at most one concurrent writer per connection, but call(),
+	// sendSubscribeTicker and sendSubscribeCandles can all be invoked from
+	// different goroutines at once (readLoop's resubscribeAll on reconnect,
+	// vs. a client-driven SubscribeTicker/SubscribeCandles from the /ws
+	// dynamic-subscribe path), so it's kept separate from mu, which only
+	// ever guards state held for the duration of a map lookup.
+	writeMu sync.Mutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newHitbtcExchange() Exchange {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &hitbtcExchange{
+		tickerChans: make(map[string][]chan<- Ticker),
+		candleChans: make(map[string][]chan<- Kline),
+		tickerSubs:  make(map[string]struct{}),
+		candleSubs:  make(map[string]candleSub),
+		pending:     make(map[int]chan []byte),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// nextRequestID returns a fresh, process-unique JSON-RPC request id so
+// concurrent calls never collide in the pending map.
+func (h *hitbtcExchange) nextRequestID() int {
+	return int(atomic.AddUint64(&h.nextReqID, 1))
+}
+
+// writeMessage serializes every write to conn behind writeMu, so concurrent
+// callers (a reconnect's resubscribeAll vs. a client-driven subscribe, or
+// two client-driven subscribes racing each other) never interleave frames
+// on the wire.
+func (h *hitbtcExchange) writeMessage(conn *websocket.Conn, data []byte) error {
+	h.writeMu.Lock()
+	defer h.writeMu.Unlock()
+	return conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// call writes command (which must carry id) and blocks until readLoop
+// delivers the matching reply, the connection drops, or h.ctx is done.
+func (h *hitbtcExchange) call(id int, command []byte) ([]byte, error) {
+	ch := make(chan []byte, 1)
+	h.mu.Lock()
+	h.pending[id] = ch
+	conn := h.conn
+	h.mu.Unlock()
+
+	if conn == nil {
+		return nil, errors.New("hitbtc: not connected")
+	}
+	if err := h.writeMessage(conn, command); err != nil {
+		h.mu.Lock()
+		delete(h.pending, id)
+		h.mu.Unlock()
+		return nil, err
+	}
+
+	select {
+	case resp, ok := <-ch:
+		if !ok {
+			return nil, errors.New("hitbtc: connection lost while waiting for response")
+		}
+		return resp, nil
+	case <-h.ctx.Done():
+		h.mu.Lock()
+		delete(h.pending, id)
+		h.mu.Unlock()
+		return nil, h.ctx.Err()
+	}
+}
+
+func (h *hitbtcExchange) Name() string {
+	return "hitbtc"
+}
+
+// Close stops any in-flight reconnect attempt, the ping and read loops, and
+// closes the underlying connection. Safe to call even if never connected.
+func (h *hitbtcExchange) Close() {
+	h.cancel()
+	h.mu.Lock()
+	conn := h.conn
+	h.conn = nil
+	h.mu.Unlock()
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+// connect dials the HitBTC websocket API, lazily, on first use, and starts
+// the read/ping loops the first time it's called.
+func (h *hitbtcExchange) connect() error {
+	h.mu.Lock()
+	connected := h.conn != nil
+	h.mu.Unlock()
+	if connected {
+		return nil
+	}
+	return h.reconnect()
+}
+
+// dialWithBackoff dials the HitBTC websocket API, retrying with jittered
+// exponential backoff (100ms -> 30s cap) until it succeeds or ctx is done.
+func dialWithBackoff(ctx context.Context) (*websocket.Conn, error) {
+	backoff := minReconnectBackoff
+	for {
+		u := url.URL{Scheme: "wss", Host: *addr, Path: "/api/2/ws"}
+		c, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+		if err == nil {
+			return c, nil
+		}
+		log.Printf("hitbtc: dial failed, retrying in %v: %v", backoff, err)
+
+		jitter := time.Duration(rand.Int63n(int64(backoff)))
+		select {
+		case <-time.After(backoff/2 + jitter/2):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		backoff *= 2
+		if backoff > maxReconnectBackoff {
+			backoff = maxReconnectBackoff
+		}
+	}
+}
+
+// reconnect dials a fresh connection, arms its ping/pong keepalive, starts
+// the read and ping loops on first use, and replays every ticker/candle
+// subscription so callers see a live feed as though it had never dropped.
+func (h *hitbtcExchange) reconnect() error {
+	c, err := dialWithBackoff(h.ctx)
+	if err != nil {
+		return err
+	}
+	c.SetReadDeadline(time.Now().Add(pongWait))
+	c.SetPongHandler(func(string) error {
+		return c.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	h.mu.Lock()
+	h.conn = c
+	startLoops := !h.readLoopOn
+	if startLoops {
+		h.readLoopOn = true
+	}
+	h.mu.Unlock()
+
+	if startLoops {
+		go h.readLoop()
+		go h.pingLoop()
+	}
+
+	return h.resubscribeAll()
+}
+
+// resubscribeAll reissues subscribeTicker/subscribeCandles for every symbol
+// tracked in tickerSubs/candleSubs, e.g. after a reconnect.
+func (h *hitbtcExchange) resubscribeAll() error {
+	h.mu.Lock()
+	tickerSymbols := make([]string, 0, len(h.tickerSubs))
+	for symbol := range h.tickerSubs {
+		tickerSymbols = append(tickerSymbols, symbol)
+	}
+	candleSubs := make([]candleSub, 0, len(h.candleSubs))
+	for _, cs := range h.candleSubs {
+		candleSubs = append(candleSubs, cs)
+	}
+	h.mu.Unlock()
+
+	for _, symbol := range tickerSymbols {
+		if err := h.sendSubscribeTicker(symbol); err != nil {
+			return err
+		}
+	}
+	for _, cs := range candleSubs {
+		if err := h.sendSubscribeCandles(cs.symbol, cs.period); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pingLoop sends a websocket ping every pingPeriod so a dead upstream
+// connection is detected (via a failed write, or the read deadline
+// SetPongHandler no longer resets) well before the OS notices.
+func (h *hitbtcExchange) pingLoop() {
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.ctx.Done():
+			return
+		case <-ticker.C:
+			h.mu.Lock()
+			conn := h.conn
+			h.mu.Unlock()
+			if conn == nil {
+				continue
+			}
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(pingWriteWait)); err != nil {
+				log.Println("hitbtc: ping failed:", err)
+			}
+		}
+	}
+}
+
+// Subscribe starts streaming ticker updates for symbol onto ch and tracks
+// it so the subscription is replayed after a reconnect. This is the entry
+// point dynamic, client-driven subscriptions (e.g. from the /ws endpoint)
+// use to add symbols beyond what's configured at startup.
+func (h *hitbtcExchange) Subscribe(symbol string, ch chan<- Ticker) error {
+	return h.SubscribeTicker(ParsePair(symbol), ch)
+}
+
+// Unsubscribe removes ch from symbol's delivery list, so dispatchTicker
+// stops sending to it, and drops symbol's ticker subscription from being
+// replayed after a reconnect once no channel is left registered for it.
+// HitBTC has no unsubscribeTicker command, so any update already in flight
+// for it may still arrive once more.
+func (h *hitbtcExchange) Unsubscribe(symbol string, ch chan<- Ticker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	chans := h.tickerChans[symbol]
+	for i, c := range chans {
+		if c == ch {
+			chans = append(chans[:i:i], chans[i+1:]...)
+			break
+		}
+	}
+	if len(chans) == 0 {
+		delete(h.tickerChans, symbol)
+		delete(h.tickerSubs, symbol)
+	} else {
+		h.tickerChans[symbol] = chans
+	}
+}
+
+// GetSymbol executes the GetSymbol API (https://api.hitbtc.com/#get-symbols)
+func (h *hitbtcExchange) GetSymbol(pair CurrencyPair) (*SymbolInfo, error) {
+	if err := h.connect(); err != nil {
+		return nil, err
+	}
+	id := h.nextRequestID()
+	commandStruct := getSymbolCommand{
+		Method: "getSymbol",
+		Params: symbolParam{
+			Symbol: pair.Symbol(),
+		},
+		ID: id,
+	}
+	commandString, _ := json.Marshal(&commandStruct)
+	message, err := h.call(id, commandString)
+	if err != nil {
+		log.Println("hitbtc: getSymbol failed", err)
+		return nil, err
+	}
+	gs := getSymbolResponse{}
+	if err := json.Unmarshal(message, &gs); err != nil {
+		log.Println("hitbtc: failed to unmarshal response to getSymbol command", err)
+		return nil, err
+	}
+	return &SymbolInfo{
+		ID:                   gs.Result.ID,
+		BaseCurrency:         gs.Result.BaseCurrency,
+		QuoteCurrency:        gs.Result.QuoteCurrency,
+		QuantityIncrement:    gs.Result.QuantityIncrement,
+		TickSize:             gs.Result.TickSize,
+		TakeLiquidityRate:    gs.Result.TakeLiquidityRate,
+		ProvideLiquidityRate: gs.Result.ProvideLiquidityRate,
+		FeeCurrency:          gs.Result.FeeCurrency,
+	}, nil
+}
+
+// GetCurrency executes the GetCurrency API (https://api.hitbtc.com/#get-currencies)
+func (h *hitbtcExchange) GetCurrency(cur Currency) (*CurrencyInfo, error) {
+	if err := h.connect(); err != nil {
+		return nil, err
+	}
+	id := h.nextRequestID()
+	commandStruct := getCurrencyCommand{
+		Method: "getCurrency",
+		Params: getCurrencyParam{
+			Currency: string(cur),
+		},
+		ID: id,
+	}
+	commandString, _ := json.Marshal(&commandStruct)
+	message, err := h.call(id, commandString)
+	if err != nil {
+		log.Println("hitbtc: getCurrency failed", err)
+		return nil, err
+	}
+	gcs := getCurrencyResponse{}
+	if err := json.Unmarshal(message, &gcs); err != nil {
+		log.Println("hitbtc: failed to unmarshal response to getCurrency command", err)
+		return nil, err
+	}
+	return &CurrencyInfo{
+		ID:       gcs.Result.ID,
+		FullName: gcs.Result.FullName,
+		Crypto:   gcs.Result.Crypto,
+	}, nil
+}
+
+// SubscribeTicker executes the subscribeTicker API
+// (https://api.hitbtc.com/#subscribe-to-ticker), registers ch to receive
+// every subsequent tickerUpdate for pair, and tracks the symbol so the
+// subscription survives a reconnect.
+func (h *hitbtcExchange) SubscribeTicker(pair CurrencyPair, ch chan<- Ticker) error {
+	if err := h.connect(); err != nil {
+		return err
+	}
+	symbol := pair.Symbol()
+
+	h.mu.Lock()
+	h.tickerChans[symbol] = append(h.tickerChans[symbol], ch)
+	h.tickerSubs[symbol] = struct{}{}
+	h.mu.Unlock()
+
+	return h.sendSubscribeTicker(symbol)
+}
+
+// sendSubscribeTicker writes the wire-level subscribeTicker command, without
+// touching any channel registrations. Used both by SubscribeTicker and by
+// resubscribeAll after a reconnect.
+func (h *hitbtcExchange) sendSubscribeTicker(symbol string) error {
+	commandStruct := subscribeTickerCommand{
+		Method: "subscribeTicker",
+		Params: symbolParam{
+			Symbol: symbol,
+		},
+		ID: 123,
+	}
+	commandString, _ := json.Marshal(&commandStruct)
+
+	h.mu.Lock()
+	conn := h.conn
+	h.mu.Unlock()
+	return h.writeMessage(conn, commandString)
+}
+
+// GetKlines executes the getCandles API (https://api.hitbtc.com/#candles)
+func (h *hitbtcExchange) GetKlines(pair CurrencyPair, period KlinePeriod, limit int) ([]Kline, error) {
+	if err := h.connect(); err != nil {
+		return nil, err
+	}
+	id := h.nextRequestID()
+	commandStruct := candlesCommand{
+		Method: "getCandles",
+		Params: candlesParam{
+			Symbol: pair.Symbol(),
+			Period: string(period),
+			Limit:  limit,
+		},
+		ID: id,
+	}
+	commandString, _ := json.Marshal(&commandStruct)
+	message, err := h.call(id, commandString)
+	if err != nil {
+		log.Println("hitbtc: getCandles failed", err)
+		return nil, err
+	}
+	gcr := getCandlesResponse{}
+	if err := json.Unmarshal(message, &gcr); err != nil {
+		log.Println("hitbtc: failed to unmarshal response to getCandles command", err)
+		return nil, err
+	}
+	result := make([]Kline, len(gcr.Result))
+	for i, c := range gcr.Result {
+		result[i] = hitbtcCandleToKline(pair.Symbol(), period, c)
+	}
+	return result, nil
+}
+
+// SubscribeCandles executes the subscribeCandles API
+// (https://api.hitbtc.com/#subscribe-to-candles), registers ch to receive
+// every subsequent candle update for pair at period, and tracks the
+// subscription so it survives a reconnect.
+func (h *hitbtcExchange) SubscribeCandles(pair CurrencyPair, period KlinePeriod, ch chan<- Kline) error {
+	if err := h.connect(); err != nil {
+		return err
+	}
+	symbol := pair.Symbol()
+	key := candleKey(symbol, period)
+
+	h.mu.Lock()
+	h.candleChans[key] = append(h.candleChans[key], ch)
+	h.candleSubs[key] = candleSub{symbol: symbol, period: period}
+	h.mu.Unlock()
+
+	return h.sendSubscribeCandles(symbol, period)
+}
+
+// sendSubscribeCandles writes the wire-level subscribeCandles command,
+// without touching any channel registrations. Used both by
+// SubscribeCandles and by resubscribeAll after a reconnect.
+func (h *hitbtcExchange) sendSubscribeCandles(symbol string, period KlinePeriod) error {
+	commandStruct := candlesCommand{
+		Method: "subscribeCandles",
+		Params: candlesParam{
+			Symbol: symbol,
+			Period: string(period),
+			Limit:  1,
+		},
+		ID: 123,
+	}
+	commandString, _ := json.Marshal(&commandStruct)
+
+	h.mu.Lock()
+	conn := h.conn
+	h.mu.Unlock()
+	return h.writeMessage(conn, commandString)
+}
+
+// readLoop reads frames off the shared connection and forwards ticker
+// updates and candle updates to the channels registered for their symbol.
+// On a read error it closes the connection and redials (replaying every
+// subscription) rather than giving up, so a dropped upstream connection
+// never surfaces as a dead feed to callers.
+func (h *hitbtcExchange) readLoop() {
+	for {
+		h.mu.Lock()
+		conn := h.conn
+		h.mu.Unlock()
+		if conn == nil {
+			return
+		}
+
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			log.Println("hitbtc: read:", err)
+			conn.Close()
+
+			h.mu.Lock()
+			if h.conn == conn {
+				h.conn = nil
+			}
+			// The old connection is gone, so nothing will ever answer these
+			// requests; wake their callers with an error instead of leaving
+			// them blocked until h.ctx is done.
+			for id, waiter := range h.pending {
+				delete(h.pending, id)
+				close(waiter)
+			}
+			h.mu.Unlock()
+
+			if h.ctx.Err() != nil {
+				return
+			}
+			if err := h.reconnect(); err != nil {
+				log.Println("hitbtc: reconnect failed:", err)
+				return
+			}
+			continue
+		}
+
+		var envelope struct {
+			Method string `json:"method"`
+			ID     int    `json:"id"`
+		}
+		if err := json.Unmarshal(message, &envelope); err != nil {
+			log.Println("hitbtc: failed to unmarshal frame", err)
+			continue
+		}
+
+		switch envelope.Method {
+		case "ticker":
+			h.dispatchTicker(message)
+		case "snapshotCandles", "updateCandles":
+			h.dispatchCandles(message)
+		case "":
+			// A plain JSON-RPC reply to a getSymbol/getCurrency/getCandles
+			// call (or an ack for a subscribeTicker/subscribeCandles command
+			// nobody is waiting on) rather than a push. Deliver it to
+			// whichever call() registered this id, if any.
+			h.mu.Lock()
+			waiter, ok := h.pending[envelope.ID]
+			if ok {
+				delete(h.pending, envelope.ID)
+			}
+			h.mu.Unlock()
+			if ok {
+				waiter <- message
+			}
+		}
+	}
+}
+
+// dispatchTicker unmarshals a ticker push and forwards it to every channel
+// subscribed to its symbol.
+func (h *hitbtcExchange) dispatchTicker(message []byte) {
+	tu := tickerUpdate{}
+	if err := json.Unmarshal(message, &tu); err != nil {
+		log.Println("hitbtc: failed to unmarshal ticker update", err)
+		return
+	}
+	t := Ticker{
+		Symbol:      tu.Params.Symbol,
+		Ask:         tu.Params.Ask,
+		Bid:         tu.Params.Bid,
+		Last:        tu.Params.Last,
+		Open:        tu.Params.Open,
+		Low:         tu.Params.Low,
+		High:        tu.Params.High,
+		Volume:      tu.Params.Volume,
+		VolumeQuote: tu.Params.VolumeQuote,
+		Timestamp:   tu.Params.Timestamp,
+	}
+
+	h.mu.Lock()
+	chans := h.tickerChans[t.Symbol]
+	h.mu.Unlock()
+	for _, ch := range chans {
+		ch <- t
+	}
+}
+
+// dispatchCandles unmarshals a snapshotCandles/updateCandles push and
+// forwards each candle to every channel subscribed to its symbol at its
+// period; a symbol subscribed at several periods at once only receives the
+// candles for the period it asked for.
+func (h *hitbtcExchange) dispatchCandles(message []byte) {
+	var cu candlesUpdate
+	if err := json.Unmarshal(message, &cu); err != nil {
+		log.Println("hitbtc: failed to unmarshal candles update", err)
+		return
+	}
+	period := KlinePeriod(cu.Params.Period)
+
+	h.mu.Lock()
+	chans := h.candleChans[candleKey(cu.Params.Symbol, period)]
+	h.mu.Unlock()
+	if len(chans) == 0 {
+		return
+	}
+	for _, c := range cu.Params.Data {
+		k := hitbtcCandleToKline(cu.Params.Symbol, period, c)
+		for _, ch := range chans {
+			ch <- k
+		}
+	}
+}
@@ -0,0 +1,458 @@
+package exchange
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newTestHitbtcExchange wires up a hitbtcExchange against a real websocket
+// connection to an httptest server, bypassing connect()/dialWithBackoff
+// (which hardcode wss://api.hitbtc.com) so call(), readLoop, and the
+// dispatch/write paths can be exercised without a live upstream. The
+// returned serverConn lets the test play the far end of the protocol.
+func newTestHitbtcExchange(t *testing.T) (*hitbtcExchange, *websocket.Conn) {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	serverConnCh := make(chan *websocket.Conn, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("server upgrade: %v", err)
+			return
+		}
+		serverConnCh <- c
+	}))
+	t.Cleanup(ts.Close)
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http")
+	clientConn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial test server: %v", err)
+	}
+	t.Cleanup(func() { clientConn.Close() })
+
+	var serverConn *websocket.Conn
+	select {
+	case serverConn = <-serverConnCh:
+	case <-time.After(2 * time.Second):
+		t.Fatal("server never accepted the websocket upgrade")
+	}
+	t.Cleanup(func() { serverConn.Close() })
+
+	h := newHitbtcExchange().(*hitbtcExchange)
+	h.conn = clientConn
+	h.readLoopOn = true
+	go h.readLoop()
+	t.Cleanup(h.cancel)
+
+	return h, serverConn
+}
+
+func TestHitbtcCallDeliversMatchingReply(t *testing.T) {
+	h, serverConn := newTestHitbtcExchange(t)
+
+	go func() {
+		_, msg, err := serverConn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var req struct {
+			ID int `json:"id"`
+		}
+		if err := json.Unmarshal(msg, &req); err != nil {
+			t.Errorf("server: unmarshal request: %v", err)
+			return
+		}
+		reply, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "result": "ok", "id": req.ID})
+		serverConn.WriteMessage(websocket.TextMessage, reply)
+	}()
+
+	id := h.nextRequestID()
+	cmd, _ := json.Marshal(map[string]interface{}{"method": "getSymbol", "id": id})
+	resp, err := h.call(id, cmd)
+	if err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	var got struct {
+		Result string `json:"result"`
+	}
+	if err := json.Unmarshal(resp, &got); err != nil {
+		t.Fatalf("unmarshal reply: %v", err)
+	}
+	if got.Result != "ok" {
+		t.Errorf("result = %q, want %q", got.Result, "ok")
+	}
+}
+
+func TestHitbtcWriteMessageSerializesConcurrentWrites(t *testing.T) {
+	h, serverConn := newTestHitbtcExchange(t)
+
+	const writers = 20
+	received := make(chan []byte, writers)
+	go func() {
+		for i := 0; i < writers; i++ {
+			_, msg, err := serverConn.ReadMessage()
+			if err != nil {
+				return
+			}
+			received <- msg
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := h.writeMessage(h.conn, []byte(`{"n":1}`)); err != nil {
+				t.Errorf("writeMessage: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < writers; i++ {
+		select {
+		case <-received:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("server only received %d/%d frames intact", i, writers)
+		}
+	}
+}
+
+func TestHitbtcDispatchTickerFansOutToEveryChannel(t *testing.T) {
+	h := newHitbtcExchange().(*hitbtcExchange)
+	chA := make(chan Ticker, 1)
+	chB := make(chan Ticker, 1)
+	h.tickerChans["BTCUSD"] = []chan<- Ticker{chA, chB}
+
+	push := `{"jsonrpc":"2.0","method":"ticker","params":{"symbol":"BTCUSD","last":"100"}}`
+	h.dispatchTicker([]byte(push))
+
+	for _, ch := range []chan Ticker{chA, chB} {
+		select {
+		case got := <-ch:
+			if got.Symbol != "BTCUSD" || got.Last != "100" {
+				t.Errorf("got %+v, want Symbol=BTCUSD Last=100", got)
+			}
+		default:
+			t.Error("dispatchTicker did not deliver to a registered channel")
+		}
+	}
+}
+
+func TestHitbtcDispatchCandlesRoutesBySymbol(t *testing.T) {
+	h := newHitbtcExchange().(*hitbtcExchange)
+	ch := make(chan Kline, 2)
+	h.candleChans[candleKey("BTCUSD", KlineM1)] = []chan<- Kline{ch}
+
+	push := `{"method":"updateCandles","params":{"symbol":"BTCUSD","period":"M1","data":[
+		{"timestamp":"2021-01-01T00:00:00.000Z","open":"1","close":"2","min":"0","max":"3"}
+	]}}`
+	h.dispatchCandles([]byte(push))
+
+	select {
+	case k := <-ch:
+		if k.Symbol != "BTCUSD" || k.Period != KlineM1 || k.Open != "1" || k.Close != "2" {
+			t.Errorf("got %+v, want the pushed candle for BTCUSD at M1", k)
+		}
+	default:
+		t.Error("dispatchCandles did not deliver to the registered channel")
+	}
+}
+
+func TestHitbtcDispatchCandlesOnlyReachesMatchingPeriod(t *testing.T) {
+	h := newHitbtcExchange().(*hitbtcExchange)
+	m1 := make(chan Kline, 2)
+	h1 := make(chan Kline, 2)
+	h.candleChans[candleKey("BTCUSD", KlineM1)] = []chan<- Kline{m1}
+	h.candleChans[candleKey("BTCUSD", KlineH1)] = []chan<- Kline{h1}
+
+	push := `{"method":"updateCandles","params":{"symbol":"BTCUSD","period":"H1","data":[
+		{"timestamp":"2021-01-01T00:00:00.000Z","open":"1","close":"2","min":"0","max":"3"}
+	]}}`
+	h.dispatchCandles([]byte(push))
+
+	select {
+	case <-m1:
+		t.Error("dispatchCandles delivered an H1 push to the M1 channel")
+	default:
+	}
+	select {
+	case k := <-h1:
+		if k.Period != KlineH1 {
+			t.Errorf("got period %v, want %v", k.Period, KlineH1)
+		}
+	default:
+		t.Error("dispatchCandles did not deliver the H1 push to the H1 channel")
+	}
+}
+
+func TestHitbtcUnsubscribeRemovesOnlyItsOwnChannel(t *testing.T) {
+	h := newHitbtcExchange().(*hitbtcExchange)
+	chA := make(chan Ticker, 1)
+	chB := make(chan Ticker, 1)
+	h.tickerChans["BTCUSD"] = []chan<- Ticker{chA, chB}
+	h.tickerSubs["BTCUSD"] = struct{}{}
+
+	h.Unsubscribe("BTCUSD", chA)
+
+	remaining := h.tickerChans["BTCUSD"]
+	if len(remaining) != 1 || remaining[0] != chan<- Ticker(chB) {
+		t.Fatalf("tickerChans[BTCUSD] = %v, want only chB left", remaining)
+	}
+	if _, stillTracked := h.tickerSubs["BTCUSD"]; !stillTracked {
+		t.Error("tickerSubs entry dropped even though a channel is still registered")
+	}
+}
+
+func TestHitbtcUnsubscribeLastChannelDropsSubscription(t *testing.T) {
+	h := newHitbtcExchange().(*hitbtcExchange)
+	ch := make(chan Ticker, 1)
+	h.tickerChans["BTCUSD"] = []chan<- Ticker{ch}
+	h.tickerSubs["BTCUSD"] = struct{}{}
+
+	h.Unsubscribe("BTCUSD", ch)
+
+	if _, ok := h.tickerChans["BTCUSD"]; ok {
+		t.Error("tickerChans[BTCUSD] should be gone once its last channel is unsubscribed")
+	}
+	if _, ok := h.tickerSubs["BTCUSD"]; ok {
+		t.Error("tickerSubs[BTCUSD] should be gone once its last channel is unsubscribed, so it isn't replayed on reconnect")
+	}
+}
+
+func TestHitbtcName(t *testing.T) {
+	h := newHitbtcExchange()
+	if got := h.Name(); got != "hitbtc" {
+		t.Errorf("Name() = %q, want %q", got, "hitbtc")
+	}
+}
+
+func TestHitbtcGetSymbol(t *testing.T) {
+	h, serverConn := newTestHitbtcExchange(t)
+
+	go func() {
+		_, msg, err := serverConn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var req struct {
+			ID int `json:"id"`
+		}
+		json.Unmarshal(msg, &req)
+		reply, _ := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result": map[string]interface{}{
+				"id":            "BTCUSD",
+				"baseCurrency":  "BTC",
+				"quoteCurrency": "USD",
+				"tickSize":      "0.01",
+			},
+		})
+		serverConn.WriteMessage(websocket.TextMessage, reply)
+	}()
+
+	info, err := h.GetSymbol(CurrencyPair{Base: "BTC", Quote: "USD"})
+	if err != nil {
+		t.Fatalf("GetSymbol: %v", err)
+	}
+	if info.ID != "BTCUSD" || info.BaseCurrency != "BTC" || info.TickSize != "0.01" {
+		t.Errorf("GetSymbol = %+v, want the fields from the server's reply", info)
+	}
+}
+
+func TestHitbtcGetCurrency(t *testing.T) {
+	h, serverConn := newTestHitbtcExchange(t)
+
+	go func() {
+		_, msg, err := serverConn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var req struct {
+			ID int `json:"id"`
+		}
+		json.Unmarshal(msg, &req)
+		reply, _ := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result": map[string]interface{}{
+				"id":       "BTC",
+				"fullName": "Bitcoin",
+				"crypto":   true,
+			},
+		})
+		serverConn.WriteMessage(websocket.TextMessage, reply)
+	}()
+
+	info, err := h.GetCurrency("BTC")
+	if err != nil {
+		t.Fatalf("GetCurrency: %v", err)
+	}
+	if info.ID != "BTC" || info.FullName != "Bitcoin" || !info.Crypto {
+		t.Errorf("GetCurrency = %+v, want the fields from the server's reply", info)
+	}
+}
+
+func TestHitbtcGetKlines(t *testing.T) {
+	h, serverConn := newTestHitbtcExchange(t)
+
+	go func() {
+		_, msg, err := serverConn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var req struct {
+			ID int `json:"id"`
+		}
+		json.Unmarshal(msg, &req)
+		reply, _ := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result": []map[string]interface{}{
+				{"timestamp": "2021-01-01T00:00:00.000Z", "open": "1", "close": "2", "min": "0", "max": "3"},
+			},
+		})
+		serverConn.WriteMessage(websocket.TextMessage, reply)
+	}()
+
+	klines, err := h.GetKlines(CurrencyPair{Base: "BTC", Quote: "USD"}, KlineM1, 1)
+	if err != nil {
+		t.Fatalf("GetKlines: %v", err)
+	}
+	if len(klines) != 1 || klines[0].Open != "1" || klines[0].Close != "2" {
+		t.Errorf("GetKlines = %+v, want the single seeded candle", klines)
+	}
+}
+
+func TestHitbtcSubscribeTickerRegistersChannelAndSendsCommand(t *testing.T) {
+	h, serverConn := newTestHitbtcExchange(t)
+
+	commandCh := make(chan string, 1)
+	go func() {
+		_, msg, err := serverConn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var req struct {
+			Method string `json:"method"`
+		}
+		json.Unmarshal(msg, &req)
+		commandCh <- req.Method
+	}()
+
+	ch := make(chan Ticker, 1)
+	if err := h.SubscribeTicker(CurrencyPair{Base: "BTC", Quote: "USD"}, ch); err != nil {
+		t.Fatalf("SubscribeTicker: %v", err)
+	}
+
+	select {
+	case method := <-commandCh:
+		if method != "subscribeTicker" {
+			t.Errorf("wire command method = %q, want %q", method, "subscribeTicker")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("server never received the subscribeTicker command")
+	}
+
+	h.mu.Lock()
+	_, subscribed := h.tickerSubs["BTCUSD"]
+	chans := h.tickerChans["BTCUSD"]
+	h.mu.Unlock()
+	if !subscribed || len(chans) != 1 {
+		t.Errorf("tickerSubs/tickerChans not updated for BTCUSD: subscribed=%v chans=%v", subscribed, chans)
+	}
+}
+
+func TestHitbtcSubscribeCandlesRegistersChannelAndSendsCommand(t *testing.T) {
+	h, serverConn := newTestHitbtcExchange(t)
+
+	commandCh := make(chan string, 1)
+	go func() {
+		_, msg, err := serverConn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var req struct {
+			Method string `json:"method"`
+		}
+		json.Unmarshal(msg, &req)
+		commandCh <- req.Method
+	}()
+
+	ch := make(chan Kline, 1)
+	if err := h.SubscribeCandles(CurrencyPair{Base: "BTC", Quote: "USD"}, KlineM1, ch); err != nil {
+		t.Fatalf("SubscribeCandles: %v", err)
+	}
+
+	select {
+	case method := <-commandCh:
+		if method != "subscribeCandles" {
+			t.Errorf("wire command method = %q, want %q", method, "subscribeCandles")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("server never received the subscribeCandles command")
+	}
+
+	h.mu.Lock()
+	cs, subscribed := h.candleSubs[candleKey("BTCUSD", KlineM1)]
+	h.mu.Unlock()
+	if !subscribed || cs.period != KlineM1 {
+		t.Errorf("candleSubs not updated for BTCUSD at M1: subscribed=%v cs=%v", subscribed, cs)
+	}
+}
+
+func TestHitbtcSubscribeWrapsSubscribeTicker(t *testing.T) {
+	h, serverConn := newTestHitbtcExchange(t)
+	go func() {
+		for {
+			if _, _, err := serverConn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ch := make(chan Ticker, 1)
+	if err := h.Subscribe("BTCUSD", ch); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	h.mu.Lock()
+	_, subscribed := h.tickerSubs["BTCUSD"]
+	h.mu.Unlock()
+	if !subscribed {
+		t.Error("Subscribe(\"BTCUSD\", ...) did not register a ticker subscription for BTCUSD")
+	}
+}
+
+func TestHitbtcClose(t *testing.T) {
+	h, _ := newTestHitbtcExchange(t)
+	h.Close()
+
+	h.mu.Lock()
+	conn := h.conn
+	h.mu.Unlock()
+	if conn != nil {
+		t.Error("Close() should clear h.conn")
+	}
+	if h.ctx.Err() == nil {
+		t.Error("Close() should cancel h.ctx")
+	}
+}
+
+func TestHitbtcCandleToKline(t *testing.T) {
+	c := hitbtcCandle{Open: "1", Close: "2", Min: "0.5", Max: "2.5", Volume: "10", VolumeQuote: "20"}
+	k := hitbtcCandleToKline("BTCUSD", KlineH1, c)
+	if k.Symbol != "BTCUSD" || k.Period != KlineH1 || k.Open != "1" || k.Close != "2" || k.Low != "0.5" || k.High != "2.5" {
+		t.Errorf("hitbtcCandleToKline(%+v) = %+v, want min/max mapped to low/high and period preserved", c, k)
+	}
+}
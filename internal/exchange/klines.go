@@ -0,0 +1,84 @@
+package exchange
+
+import (
+	"fmt"
+	"sync"
+)
+
+// DefaultKlineCapacity bounds how many candles a KlineStore retains per
+// symbol by default.
+const DefaultKlineCapacity = 500
+
+// klineKey identifies one symbol's candles at one period, since a symbol
+// can be cached at several granularities at once.
+type klineKey struct {
+	symbol string
+	period KlinePeriod
+}
+
+// KlineStore caches the last N candles per (symbol, period), protected the
+// same way the currency store is: one lock, held only around the read or
+// write of a single buffer.
+type KlineStore struct {
+	mu       sync.Mutex
+	buffers  map[klineKey][]Kline
+	capacity int
+}
+
+// NewKlineStore builds a KlineStore retaining up to capacity candles per
+// (symbol, period).
+func NewKlineStore(capacity int) *KlineStore {
+	return &KlineStore{buffers: make(map[klineKey][]Kline), capacity: capacity}
+}
+
+// Seed replaces symbol's cached candles at period with ks, most recent last.
+func (s *KlineStore) Seed(symbol string, period KlinePeriod, ks []Kline) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(ks) > s.capacity {
+		ks = ks[len(ks)-s.capacity:]
+	}
+	s.buffers[klineKey{symbol, period}] = append([]Kline(nil), ks...)
+}
+
+// Add appends a live candle update for symbol at period, dropping the
+// oldest entry once the buffer is at capacity.
+func (s *KlineStore) Add(symbol string, period KlinePeriod, k Kline) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := klineKey{symbol, period}
+	buf := append(s.buffers[key], k)
+	if len(buf) > s.capacity {
+		buf = buf[len(buf)-s.capacity:]
+	}
+	s.buffers[key] = buf
+}
+
+// Get returns up to limit of the most recent candles cached for symbol at
+// period.
+func (s *KlineStore) Get(symbol string, period KlinePeriod, limit int) []Kline {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	buf := s.buffers[klineKey{symbol, period}]
+	if limit <= 0 || limit > len(buf) {
+		limit = len(buf)
+	}
+	result := make([]Kline, limit)
+	copy(result, buf[len(buf)-limit:])
+	return result
+}
+
+// ParseKlinePeriod validates s as one of AllKlinePeriods, defaulting to
+// KlineM1 when s is empty.
+func ParseKlinePeriod(s string) (KlinePeriod, error) {
+	if s == "" {
+		return KlineM1, nil
+	}
+	period := KlinePeriod(s)
+	for _, valid := range AllKlinePeriods {
+		if period == valid {
+			return period, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported period %v", s)
+}
@@ -0,0 +1,99 @@
+package exchange
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseKlinePeriod(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    KlinePeriod
+		wantErr bool
+	}{
+		{"", KlineM1, false},
+		{"M1", KlineM1, false},
+		{"M5", KlineM5, false},
+		{"H1", KlineH1, false},
+		{"D1", KlineD1, false},
+		{"bogus", "", true},
+	}
+	for _, c := range cases {
+		got, err := ParseKlinePeriod(c.in)
+		if (err != nil) != c.wantErr {
+			t.Errorf("ParseKlinePeriod(%q) error = %v, wantErr %v", c.in, err, c.wantErr)
+			continue
+		}
+		if err == nil && got != c.want {
+			t.Errorf("ParseKlinePeriod(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestKlineStoreSeedAndGet(t *testing.T) {
+	s := NewKlineStore(3)
+	s.Seed("BTCUSD", KlineM1, []Kline{
+		{Symbol: "BTCUSD", Timestamp: time.Unix(1, 0)},
+		{Symbol: "BTCUSD", Timestamp: time.Unix(2, 0)},
+	})
+
+	got := s.Get("BTCUSD", KlineM1, 10)
+	if len(got) != 2 {
+		t.Fatalf("got %d klines, want 2", len(got))
+	}
+	if !got[0].Timestamp.Equal(time.Unix(1, 0)) || !got[1].Timestamp.Equal(time.Unix(2, 0)) {
+		t.Errorf("got %+v, want seeded order preserved", got)
+	}
+}
+
+func TestKlineStoreAddTrimsToCapacity(t *testing.T) {
+	s := NewKlineStore(2)
+	s.Seed("BTCUSD", KlineM1, []Kline{{Symbol: "BTCUSD", Timestamp: time.Unix(1, 0)}})
+	s.Add("BTCUSD", KlineM1, Kline{Symbol: "BTCUSD", Timestamp: time.Unix(2, 0)})
+	s.Add("BTCUSD", KlineM1, Kline{Symbol: "BTCUSD", Timestamp: time.Unix(3, 0)})
+
+	got := s.Get("BTCUSD", KlineM1, 10)
+	if len(got) != 2 {
+		t.Fatalf("got %d klines, want capacity-bounded 2", len(got))
+	}
+	if !got[0].Timestamp.Equal(time.Unix(2, 0)) || !got[1].Timestamp.Equal(time.Unix(3, 0)) {
+		t.Errorf("got %+v, want the 2 most recent candles", got)
+	}
+}
+
+func TestKlineStoreGetLimit(t *testing.T) {
+	s := NewKlineStore(10)
+	s.Seed("BTCUSD", KlineM1, []Kline{
+		{Symbol: "BTCUSD", Timestamp: time.Unix(1, 0)},
+		{Symbol: "BTCUSD", Timestamp: time.Unix(2, 0)},
+		{Symbol: "BTCUSD", Timestamp: time.Unix(3, 0)},
+	})
+
+	got := s.Get("BTCUSD", KlineM1, 1)
+	if len(got) != 1 || !got[0].Timestamp.Equal(time.Unix(3, 0)) {
+		t.Errorf("Get with limit 1 = %+v, want the single most recent candle", got)
+	}
+}
+
+func TestKlineStoreGetUnknownSymbol(t *testing.T) {
+	s := NewKlineStore(10)
+	if got := s.Get("NOPE", KlineM1, 10); len(got) != 0 {
+		t.Errorf("Get for an unseeded symbol = %+v, want empty", got)
+	}
+}
+
+func TestKlineStoreKeepsPeriodsIndependent(t *testing.T) {
+	s := NewKlineStore(10)
+	s.Seed("BTCUSD", KlineM1, []Kline{{Symbol: "BTCUSD", Timestamp: time.Unix(1, 0)}})
+	s.Seed("BTCUSD", KlineH1, []Kline{
+		{Symbol: "BTCUSD", Timestamp: time.Unix(1, 0)},
+		{Symbol: "BTCUSD", Timestamp: time.Unix(2, 0)},
+	})
+
+	if got := s.Get("BTCUSD", KlineM1, 10); len(got) != 1 {
+		t.Errorf("M1 klines = %+v, want the 1 candle seeded at M1", got)
+	}
+	if got := s.Get("BTCUSD", KlineH1, 10); len(got) != 2 {
+		t.Errorf("H1 klines = %+v, want the 2 candles seeded at H1", got)
+	}
+}
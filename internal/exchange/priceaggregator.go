@@ -0,0 +1,106 @@
+package exchange
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// PriceSource is a fallback pricing backend polled when a symbol's primary
+// exchange feed goes stale, modeled on status-go's CoinGecko fallback.
+type PriceSource interface {
+	Name() string
+	FetchPrice(pair CurrencyPair) (Ticker, error)
+}
+
+// Defaults for the staleness window and fallback poll interval.
+const (
+	DefaultStalenessWindow = 30 * time.Second
+	DefaultFallbackPoll    = 10 * time.Second
+)
+
+// PriceAggregator watches how recently each symbol's primary feed has
+// updated, via noteUpdate, and once a symbol goes stale, polls fallback for
+// that symbol until the primary feed recovers.
+type PriceAggregator struct {
+	fallback PriceSource
+	pairs    map[string]CurrencyPair
+	update   func(Ticker, string)
+
+	mu             sync.Mutex
+	lastUpdate     map[string]time.Time
+	fallbackActive map[string]bool
+}
+
+// NewPriceAggregator builds a PriceAggregator that polls fallback for pairs
+// once they go stale, reporting every price (primary or fallback) through
+// update along with the name of the source that produced it.
+func NewPriceAggregator(fallback PriceSource, pairs map[string]CurrencyPair, update func(t Ticker, source string)) *PriceAggregator {
+	now := time.Now()
+	lastUpdate := make(map[string]time.Time, len(pairs))
+	for symbol := range pairs {
+		lastUpdate[symbol] = now
+	}
+	return &PriceAggregator{
+		fallback:       fallback,
+		pairs:          pairs,
+		update:         update,
+		lastUpdate:     lastUpdate,
+		fallbackActive: make(map[string]bool),
+	}
+}
+
+// NoteUpdate records that symbol was just refreshed by its primary source,
+// logging (and clearing) a fallback-active flag if the primary just
+// recovered from a period of staleness.
+func (a *PriceAggregator) NoteUpdate(symbol string) {
+	a.mu.Lock()
+	a.lastUpdate[symbol] = time.Now()
+	wasFallback := a.fallbackActive[symbol]
+	a.fallbackActive[symbol] = false
+	a.mu.Unlock()
+	if wasFallback {
+		log.Printf("%v: primary feed recovered, stopping fallback polling", symbol)
+	}
+}
+
+// staleness returns how long it has been since symbol last updated from its
+// primary source.
+func (a *PriceAggregator) staleness(symbol string) time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return time.Since(a.lastUpdate[symbol])
+}
+
+// Run starts a staleness watcher for every configured symbol.
+func (a *PriceAggregator) Run(window, pollInterval time.Duration) {
+	for symbol, pair := range a.pairs {
+		go a.watch(symbol, pair, window, pollInterval)
+	}
+}
+
+// watch polls fallback for symbol, at pollInterval, whenever the primary
+// feed has been silent for longer than window.
+func (a *PriceAggregator) watch(symbol string, pair CurrencyPair, window, pollInterval time.Duration) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if a.staleness(symbol) < window {
+			continue
+		}
+		a.mu.Lock()
+		if !a.fallbackActive[symbol] {
+			log.Printf("%v: primary feed stale, switching to %v fallback", symbol, a.fallback.Name())
+		}
+		a.fallbackActive[symbol] = true
+		a.mu.Unlock()
+
+		t, err := a.fallback.FetchPrice(pair)
+		if err != nil {
+			log.Printf("%v: fallback fetch failed: %v", symbol, err)
+			continue
+		}
+		t.Symbol = symbol
+		a.update(t, a.fallback.Name())
+	}
+}
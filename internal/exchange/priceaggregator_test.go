@@ -0,0 +1,80 @@
+package exchange
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakePriceSource is a PriceSource that returns a fixed price and counts
+// how many times it was polled.
+type fakePriceSource struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (f *fakePriceSource) Name() string { return "fake" }
+
+func (f *fakePriceSource) FetchPrice(pair CurrencyPair) (Ticker, error) {
+	f.mu.Lock()
+	f.calls++
+	f.mu.Unlock()
+	return Ticker{Last: "100"}, nil
+}
+
+func (f *fakePriceSource) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func TestPriceAggregatorFallsBackWhenStale(t *testing.T) {
+	fallback := &fakePriceSource{}
+	pairs := map[string]CurrencyPair{"BTCUSD": {Base: "BTC", Quote: "USD"}}
+
+	var mu sync.Mutex
+	var updates []string
+	update := func(t Ticker, source string) {
+		mu.Lock()
+		updates = append(updates, source)
+		mu.Unlock()
+	}
+
+	agg := NewPriceAggregator(fallback, pairs, update)
+	agg.Run(20*time.Millisecond, 10*time.Millisecond)
+
+	if fallback.callCount() != 0 {
+		t.Fatalf("fallback polled before the staleness window elapsed")
+	}
+
+	deadline := time.After(2 * time.Second)
+	for fallback.callCount() == 0 {
+		select {
+		case <-deadline:
+			t.Fatal("fallback was never polled after the primary feed went stale")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	mu.Lock()
+	if len(updates) == 0 || updates[0] != "fake" {
+		t.Errorf("updates = %v, want at least one update tagged with the fallback's name", updates)
+	}
+	mu.Unlock()
+}
+
+func TestPriceAggregatorRecoversOnNoteUpdate(t *testing.T) {
+	fallback := &fakePriceSource{}
+	pairs := map[string]CurrencyPair{"BTCUSD": {Base: "BTC", Quote: "USD"}}
+	agg := NewPriceAggregator(fallback, pairs, func(Ticker, string) {})
+
+	if agg.staleness("BTCUSD") > time.Second {
+		t.Fatalf("a freshly built aggregator should consider its symbols fresh")
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	agg.NoteUpdate("BTCUSD")
+	if agg.staleness("BTCUSD") > 5*time.Millisecond {
+		t.Errorf("staleness after NoteUpdate = %v, want close to 0", agg.staleness("BTCUSD"))
+	}
+}
@@ -0,0 +1,125 @@
+// Package httpapi wires this service's REST and websocket endpoints to a
+// store.Store and exchange.KlineStore, as handler methods on Server rather
+// than package-level functions reading global state, so they can be
+// exercised with a fake Store in tests.
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/dchoweller/gows/internal/exchange"
+	"github.com/dchoweller/gows/internal/store"
+)
+
+// Server holds the dependencies every handler needs and exposes the mux
+// router built from them.
+type Server struct {
+	store      store.Store
+	symbols    []string
+	klines     *exchange.KlineStore
+	symbolInfo map[string]*exchange.SymbolInfo
+	ws         *WebsocketServer
+}
+
+// NewServer builds a Server backed by st. symbols lists every symbol
+// tracked by st, in the order unsupported-symbol error messages should list
+// them. klines and symbolInfo may be nil if the active exchange doesn't
+// support candlestick history; the corresponding routes then 404. ex is the
+// active exchange, used so /ws can subscribe to symbols beyond the
+// configured set when ex supports it; it may be nil in tests that don't
+// exercise that path.
+func NewServer(st store.Store, symbols []string, ex exchange.Exchange, klines *exchange.KlineStore, symbolInfo map[string]*exchange.SymbolInfo) *Server {
+	var dynamicSub exchange.DynamicSubscriber
+	var exchangeName string
+	if ex != nil {
+		exchangeName = ex.Name()
+		dynamicSub, _ = ex.(exchange.DynamicSubscriber)
+	}
+	return &Server{
+		store:      st,
+		symbols:    symbols,
+		klines:     klines,
+		symbolInfo: symbolInfo,
+		ws:         newWebsocketServer(symbols, dynamicSub, exchangeName),
+	}
+}
+
+// Publish pushes info to every client subscribed to symbol over /ws. Call
+// this after every store.Update so subscribers see the same data the REST
+// endpoints would return.
+func (s *Server) Publish(symbol string, info store.CurrencyInfo) {
+	s.ws.publish(symbol, info)
+}
+
+// Router builds the mux.Router serving this service's routes.
+func (s *Server) Router() *mux.Router {
+	r := mux.NewRouter()
+	r.HandleFunc("/currency/all", s.getAllCurrencies)
+	r.HandleFunc("/currency/health", s.getHealth)
+	r.HandleFunc("/currency/{symbol}/klines", s.getKlines)
+	r.HandleFunc("/currency/{symbol}/info", s.getSymbolInfo)
+	r.HandleFunc("/currency/{symbol}", s.getSingleCurrency)
+	r.HandleFunc("/ws", s.ws.serveWs)
+	return r
+}
+
+// getSingleCurrency serves GET /currency/{symbol}.
+func (s *Server) getSingleCurrency(w http.ResponseWriter, r *http.Request) {
+	symbol := mux.Vars(r)["symbol"]
+	info, ok := s.store.Get(symbol)
+	if !ok {
+		fmt.Fprintf(w, "Unsupported symbol %v!  Use one of:\n", symbol)
+		for _, sym := range s.symbols {
+			fmt.Fprintf(w, "%v\n", sym)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(info)
+}
+
+// getAllCurrenciesResponse is the response body for GET /currency/all.
+type getAllCurrenciesResponse struct {
+	Currencies []store.CurrencyInfo `json:"currencies"`
+}
+
+// getAllCurrencies serves GET /currency/all.
+func (s *Server) getAllCurrencies(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(getAllCurrenciesResponse{Currencies: s.store.GetAll()})
+}
+
+// currencyHealth is a single symbol's entry in the GET /currency/health
+// response.
+type currencyHealth struct {
+	Symbol     string  `json:"symbol"`
+	Source     string  `json:"source"`
+	AgeSeconds float64 `json:"ageSeconds"`
+}
+
+// getHealthResponse is the response body for GET /currency/health.
+type getHealthResponse struct {
+	Currencies []currencyHealth `json:"currencies"`
+}
+
+// getHealth serves GET /currency/health: per-symbol source and last-update
+// age, so downstream consumers can detect degraded (fallback-served or
+// stale) pricing.
+func (s *Server) getHealth(w http.ResponseWriter, r *http.Request) {
+	result := make([]currencyHealth, len(s.symbols))
+	for i, symbol := range s.symbols {
+		info, _ := s.store.Get(symbol)
+		var age float64
+		if !info.UpdatedAt.IsZero() {
+			age = time.Since(info.UpdatedAt).Seconds()
+		}
+		result[i] = currencyHealth{Symbol: symbol, Source: info.Source, AgeSeconds: age}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(getHealthResponse{Currencies: result})
+}
@@ -0,0 +1,82 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dchoweller/gows/internal/store"
+)
+
+func TestGetSingleCurrency(t *testing.T) {
+	st := store.New([]string{"BTCUSD"})
+	st.Update(store.TickerUpdate{Symbol: "BTCUSD", Last: "50000", Source: "hitbtc"})
+
+	srv := NewServer(st, []string{"BTCUSD"}, nil, nil, nil)
+	req := httptest.NewRequest(http.MethodGet, "/currency/BTCUSD", nil)
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var got store.CurrencyInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if got.Last != "50000" {
+		t.Errorf("Last = %q, want %q", got.Last, "50000")
+	}
+}
+
+func TestGetSingleCurrencyUnsupportedSymbol(t *testing.T) {
+	st := store.New([]string{"BTCUSD"})
+	srv := NewServer(st, []string{"BTCUSD"}, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/currency/DOGEUSD", nil)
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if got := w.Body.String(); got == "" {
+		t.Error("expected an explanatory body for an unsupported symbol, got empty response")
+	}
+}
+
+func TestGetAllCurrencies(t *testing.T) {
+	st := store.New([]string{"BTCUSD", "ETHBTC"})
+	srv := NewServer(st, []string{"BTCUSD", "ETHBTC"}, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/currency/all", nil)
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, req)
+
+	var resp getAllCurrenciesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Currencies) != 2 {
+		t.Errorf("got %d currencies, want 2", len(resp.Currencies))
+	}
+}
+
+func TestGetHealth(t *testing.T) {
+	st := store.New([]string{"BTCUSD"})
+	st.Update(store.TickerUpdate{Symbol: "BTCUSD", Last: "50000", Source: "hitbtc"})
+	srv := NewServer(st, []string{"BTCUSD"}, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/currency/health", nil)
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, req)
+
+	var resp getHealthResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Currencies) != 1 || resp.Currencies[0].Source != "hitbtc" {
+		t.Errorf("unexpected health response: %+v", resp)
+	}
+}
@@ -0,0 +1,117 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/dchoweller/gows/internal/exchange"
+)
+
+// defaultKlineLimit is how many candles are returned when a request omits
+// the limit parameter.
+const defaultKlineLimit = 100
+
+// getKlinesResponse is the response body for GET /currency/{symbol}/klines.
+type getKlinesResponse struct {
+	Symbol string               `json:"symbol"`
+	Period exchange.KlinePeriod `json:"period"`
+	Klines []exchange.Kline     `json:"klines"`
+}
+
+// getKlines serves GET /currency/{symbol}/klines. Accepts
+// ?period=M1|M5|M15|M30|H1|H4|D1 (defaulting to M1 if omitted), ?limit=N
+// (default defaultKlineLimit), and optional RFC3339 ?from=...&to=... bounds.
+func (s *Server) getKlines(w http.ResponseWriter, r *http.Request) {
+	symbol := mux.Vars(r)["symbol"]
+	if s.klines == nil {
+		http.Error(w, "candlestick history is not available for the active exchange", http.StatusNotFound)
+		return
+	}
+	if _, ok := s.store.Get(symbol); !ok {
+		http.Error(w, fmt.Sprintf("unsupported symbol %v", symbol), http.StatusNotFound)
+		return
+	}
+
+	period, err := exchange.ParseKlinePeriod(r.URL.Query().Get("period"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultKlineLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	result := s.klines.Get(symbol, period, limit)
+	if from, err := time.Parse(time.RFC3339, r.URL.Query().Get("from")); err == nil {
+		result = filterKlinesFrom(result, from)
+	}
+	if to, err := time.Parse(time.RFC3339, r.URL.Query().Get("to")); err == nil {
+		result = filterKlinesTo(result, to)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(getKlinesResponse{Symbol: symbol, Period: period, Klines: result})
+}
+
+func filterKlinesFrom(ks []exchange.Kline, from time.Time) []exchange.Kline {
+	result := make([]exchange.Kline, 0, len(ks))
+	for _, k := range ks {
+		if !k.Timestamp.Before(from) {
+			result = append(result, k)
+		}
+	}
+	return result
+}
+
+func filterKlinesTo(ks []exchange.Kline, to time.Time) []exchange.Kline {
+	result := make([]exchange.Kline, 0, len(ks))
+	for _, k := range ks {
+		if !k.Timestamp.After(to) {
+			result = append(result, k)
+		}
+	}
+	return result
+}
+
+// symbolInfoResponse is the response body for GET /currency/{symbol}/info.
+type symbolInfoResponse struct {
+	Symbol               string `json:"symbol"`
+	BaseCurrency         string `json:"baseCurrency"`
+	QuoteCurrency        string `json:"quoteCurrency"`
+	PriceTickSize        string `json:"priceTickSize"`
+	QuantityIncrement    string `json:"quantityIncrement"`
+	FeeCurrency          string `json:"feeCurrency"`
+	TakeLiquidityRate    string `json:"takeLiquidityRate"`
+	ProvideLiquidityRate string `json:"provideLiquidityRate"`
+}
+
+// getSymbolInfo serves GET /currency/{symbol}/info.
+func (s *Server) getSymbolInfo(w http.ResponseWriter, r *http.Request) {
+	symbol := mux.Vars(r)["symbol"]
+	info, ok := s.symbolInfo[symbol]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unsupported symbol %v", symbol), http.StatusNotFound)
+		return
+	}
+	resp := symbolInfoResponse{
+		Symbol:               symbol,
+		BaseCurrency:         info.BaseCurrency,
+		QuoteCurrency:        info.QuoteCurrency,
+		PriceTickSize:        info.TickSize,
+		QuantityIncrement:    info.QuantityIncrement,
+		FeeCurrency:          info.FeeCurrency,
+		TakeLiquidityRate:    info.TakeLiquidityRate,
+		ProvideLiquidityRate: info.ProvideLiquidityRate,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
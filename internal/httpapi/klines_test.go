@@ -0,0 +1,149 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dchoweller/gows/internal/exchange"
+	"github.com/dchoweller/gows/internal/store"
+)
+
+func TestGetKlines(t *testing.T) {
+	st := store.New([]string{"BTCUSD"})
+	klines := exchange.NewKlineStore(exchange.DefaultKlineCapacity)
+	klines.Seed("BTCUSD", exchange.KlineM1, []exchange.Kline{
+		{Symbol: "BTCUSD", Timestamp: time.Unix(1, 0)},
+		{Symbol: "BTCUSD", Timestamp: time.Unix(2, 0)},
+	})
+	srv := NewServer(st, []string{"BTCUSD"}, nil, klines, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/currency/BTCUSD/klines?period=M1&limit=10", nil)
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var resp getKlinesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Klines) != 2 {
+		t.Errorf("got %d klines, want 2", len(resp.Klines))
+	}
+}
+
+func TestGetKlinesFromTo(t *testing.T) {
+	st := store.New([]string{"BTCUSD"})
+	klines := exchange.NewKlineStore(exchange.DefaultKlineCapacity)
+	klines.Seed("BTCUSD", exchange.KlineM1, []exchange.Kline{
+		{Symbol: "BTCUSD", Timestamp: time.Unix(1, 0)},
+		{Symbol: "BTCUSD", Timestamp: time.Unix(2, 0)},
+		{Symbol: "BTCUSD", Timestamp: time.Unix(3, 0)},
+	})
+	srv := NewServer(st, []string{"BTCUSD"}, nil, klines, nil)
+
+	from := time.Unix(2, 0).Format(time.RFC3339)
+	to := time.Unix(2, 0).Format(time.RFC3339)
+	req := httptest.NewRequest(http.MethodGet, "/currency/BTCUSD/klines?from="+from+"&to="+to, nil)
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, req)
+
+	var resp getKlinesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Klines) != 1 || !resp.Klines[0].Timestamp.Equal(time.Unix(2, 0)) {
+		t.Errorf("from/to filter returned %+v, want a single candle at t=2", resp.Klines)
+	}
+}
+
+func TestGetKlinesNonM1Period(t *testing.T) {
+	st := store.New([]string{"BTCUSD"})
+	klines := exchange.NewKlineStore(exchange.DefaultKlineCapacity)
+	klines.Seed("BTCUSD", exchange.KlineH1, []exchange.Kline{
+		{Symbol: "BTCUSD", Period: exchange.KlineH1, Timestamp: time.Unix(1, 0)},
+	})
+	srv := NewServer(st, []string{"BTCUSD"}, nil, klines, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/currency/BTCUSD/klines?period=H1", nil)
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var resp getKlinesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Period != exchange.KlineH1 || len(resp.Klines) != 1 {
+		t.Errorf("got %+v, want the 1 candle seeded at H1", resp)
+	}
+}
+
+func TestGetKlinesUnavailable(t *testing.T) {
+	st := store.New([]string{"BTCUSD"})
+	srv := NewServer(st, []string{"BTCUSD"}, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/currency/BTCUSD/klines", nil)
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestGetKlinesBadPeriod(t *testing.T) {
+	st := store.New([]string{"BTCUSD"})
+	klines := exchange.NewKlineStore(exchange.DefaultKlineCapacity)
+	srv := NewServer(st, []string{"BTCUSD"}, nil, klines, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/currency/BTCUSD/klines?period=bogus", nil)
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGetSymbolInfo(t *testing.T) {
+	st := store.New([]string{"BTCUSD"})
+	symbolInfo := map[string]*exchange.SymbolInfo{
+		"BTCUSD": {ID: "BTCUSD", BaseCurrency: "BTC", QuoteCurrency: "USD", TickSize: "0.01"},
+	}
+	srv := NewServer(st, []string{"BTCUSD"}, nil, nil, symbolInfo)
+
+	req := httptest.NewRequest(http.MethodGet, "/currency/BTCUSD/info", nil)
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var resp symbolInfoResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.BaseCurrency != "BTC" {
+		t.Errorf("BaseCurrency = %q, want %q", resp.BaseCurrency, "BTC")
+	}
+}
+
+func TestGetSymbolInfoUnsupportedSymbol(t *testing.T) {
+	st := store.New([]string{"BTCUSD"})
+	srv := NewServer(st, []string{"BTCUSD"}, nil, nil, map[string]*exchange.SymbolInfo{})
+
+	req := httptest.NewRequest(http.MethodGet, "/currency/BTCUSD/info", nil)
+	w := httptest.NewRecorder()
+	srv.Router().ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,375 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/dchoweller/gows/internal/exchange"
+	"github.com/dchoweller/gows/internal/store"
+)
+
+// Tunables for client-facing websocket connections, modeled after blockbook's
+// socket.io server: a buffered outbound channel per connection, a write
+// deadline on every send, and server-initiated pings to detect dead peers.
+const (
+	clientSendBufferSize = 500
+	clientWriteWait      = 10 * time.Second
+	clientPongWait       = 60 * time.Second
+	defaultTimeout       = 54 * time.Second
+)
+
+var clientUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// wsRequest is a JSON-RPC-style request sent by a connected client, e.g.
+// {"id":"1","method":"subscribeTicker","params":{"symbols":["BTCUSD"]}}
+type wsRequest struct {
+	ID     string          `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// wsSubscribeParams is the params payload for subscribeTicker/unsubscribeTicker.
+type wsSubscribeParams struct {
+	Symbols []string `json:"symbols"`
+}
+
+// wsPush is the frame pushed to a client, either an ack for a request or a
+// ticker update for a subscribed symbol.
+type wsPush struct {
+	ID   string      `json:"id"`
+	Data interface{} `json:"data"`
+}
+
+// clientConn is a single client websocket connection and its delivery state.
+type clientConn struct {
+	id   uint64
+	conn *websocket.Conn
+	send chan []byte
+
+	aliveMu sync.Mutex
+	alive   bool
+}
+
+func newClientConn(id uint64, conn *websocket.Conn) *clientConn {
+	return &clientConn{id: id, conn: conn, send: make(chan []byte, clientSendBufferSize), alive: true}
+}
+
+// close marks the connection dead and closes its send channel and socket.
+// Safe to call more than once.
+func (cc *clientConn) close() {
+	cc.aliveMu.Lock()
+	defer cc.aliveMu.Unlock()
+	if !cc.alive {
+		return
+	}
+	cc.alive = false
+	close(cc.send)
+	cc.conn.Close()
+}
+
+func (cc *clientConn) isAlive() bool {
+	cc.aliveMu.Lock()
+	defer cc.aliveMu.Unlock()
+	return cc.alive
+}
+
+// writePump drains cc.send to the underlying connection and sends a
+// server-initiated ping every defaultTimeout. Runs in its own goroutine for
+// the lifetime of the connection.
+func (cc *clientConn) writePump() {
+	ticker := time.NewTicker(defaultTimeout)
+	defer func() {
+		ticker.Stop()
+		cc.close()
+	}()
+	for {
+		select {
+		case message, ok := <-cc.send:
+			cc.conn.SetWriteDeadline(time.Now().Add(clientWriteWait))
+			if !ok {
+				cc.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := cc.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				return
+			}
+		case <-ticker.C:
+			cc.conn.SetWriteDeadline(time.Now().Add(clientWriteWait))
+			if err := cc.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// WebsocketServer tracks client subscriptions per symbol and fans out
+// currency updates to subscribed connections. It can also, when the active
+// exchange supports it, subscribe to symbols clients ask for beyond the
+// ones configured at startup.
+type WebsocketServer struct {
+	mu           sync.RWMutex
+	subs         map[string]map[*clientConn]struct{}
+	nextID       uint64
+	symbols      []string
+	knownSymbols map[string]bool
+
+	dynamicSub   exchange.DynamicSubscriber // nil if the active exchange doesn't support it
+	exchangeName string
+	dynMu        sync.Mutex
+	dynFeeds     map[string]*dynamicFeed
+}
+
+// dynamicFeed is the upstream channel and forwarding goroutine started by
+// ensureDynamicFeed for one dynamically-subscribed symbol. done lets
+// stopDynamicFeed stop the forwarding goroutine without closing ch itself,
+// since dispatchTicker may still be mid-send on it.
+type dynamicFeed struct {
+	ch   chan exchange.Ticker
+	done chan struct{}
+}
+
+// newWebsocketServer builds a WebsocketServer for the given configured
+// symbols. dynamicSub and exchangeName may be zero values if the active
+// exchange doesn't support subscribing to symbols beyond that set.
+func newWebsocketServer(symbols []string, dynamicSub exchange.DynamicSubscriber, exchangeName string) *WebsocketServer {
+	known := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		known[symbol] = true
+	}
+	return &WebsocketServer{
+		subs:         make(map[string]map[*clientConn]struct{}),
+		symbols:      symbols,
+		knownSymbols: known,
+		dynamicSub:   dynamicSub,
+		exchangeName: exchangeName,
+		dynFeeds:     make(map[string]*dynamicFeed),
+	}
+}
+
+func (ws *WebsocketServer) subscribe(symbol string, cc *clientConn) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if ws.subs[symbol] == nil {
+		ws.subs[symbol] = make(map[*clientConn]struct{})
+	}
+	ws.subs[symbol][cc] = struct{}{}
+}
+
+func (ws *WebsocketServer) unsubscribe(symbol string, cc *clientConn) {
+	ws.mu.Lock()
+	conns, ok := ws.subs[symbol]
+	empty := false
+	if ok {
+		delete(conns, cc)
+		empty = len(conns) == 0
+		if empty {
+			delete(ws.subs, symbol)
+		}
+	}
+	ws.mu.Unlock()
+	if empty {
+		ws.stopDynamicFeed(symbol)
+	}
+}
+
+func (ws *WebsocketServer) unsubscribeAll(cc *clientConn) {
+	ws.mu.Lock()
+	var emptied []string
+	for symbol, conns := range ws.subs {
+		delete(conns, cc)
+		if len(conns) == 0 {
+			delete(ws.subs, symbol)
+			emptied = append(emptied, symbol)
+		}
+	}
+	ws.mu.Unlock()
+	for _, symbol := range emptied {
+		ws.stopDynamicFeed(symbol)
+	}
+}
+
+// ensureDynamicFeed starts streaming symbol from the upstream exchange if
+// it isn't one of the symbols already subscribed at startup and the active
+// exchange supports adding symbols at runtime. Safe to call repeatedly for
+// the same symbol; only the first call for a given symbol does anything.
+func (ws *WebsocketServer) ensureDynamicFeed(symbol string) {
+	if ws.knownSymbols[symbol] || ws.dynamicSub == nil {
+		return
+	}
+	ws.dynMu.Lock()
+	defer ws.dynMu.Unlock()
+	if _, ok := ws.dynFeeds[symbol]; ok {
+		return
+	}
+
+	ch := make(chan exchange.Ticker, clientSendBufferSize)
+	if err := ws.dynamicSub.Subscribe(symbol, ch); err != nil {
+		log.Printf("ws: failed to subscribe %v upstream: %v", symbol, err)
+		return
+	}
+	feed := &dynamicFeed{ch: ch, done: make(chan struct{})}
+	ws.dynFeeds[symbol] = feed
+	go func() {
+		for {
+			select {
+			case t := <-ch:
+				ws.publish(symbol, store.CurrencyInfo{
+					Ask: t.Ask, Bid: t.Bid, Last: t.Last, Open: t.Open, Low: t.Low, High: t.High,
+					Source: ws.exchangeName, UpdatedAt: time.Now(),
+				})
+			case <-feed.done:
+				return
+			}
+		}
+	}()
+}
+
+// stopDynamicFeed tells the upstream exchange to stop streaming symbol, if
+// it was started by ensureDynamicFeed and no client is subscribed to it
+// anymore, and stops the forwarding goroutine ensureDynamicFeed started for
+// it. The channel itself is never closed: dispatchTicker may still be
+// mid-send on it, so the forwarding goroutine exits via done instead and the
+// channel is left for the garbage collector once the exchange drops its own
+// reference.
+func (ws *WebsocketServer) stopDynamicFeed(symbol string) {
+	if ws.knownSymbols[symbol] || ws.dynamicSub == nil {
+		return
+	}
+	ws.dynMu.Lock()
+	feed, ok := ws.dynFeeds[symbol]
+	if ok {
+		delete(ws.dynFeeds, symbol)
+	}
+	ws.dynMu.Unlock()
+	if !ok {
+		return
+	}
+	ws.dynamicSub.Unsubscribe(symbol, feed.ch)
+	close(feed.done)
+}
+
+// publish fans out the latest currencyInfo for symbol to every subscriber.
+// Slow consumers (full send buffer) are dropped and closed rather than
+// allowed to block the rest of the fan-out.
+func (ws *WebsocketServer) publish(symbol string, data store.CurrencyInfo) {
+	ws.mu.RLock()
+	conns := ws.subs[symbol]
+	targets := make([]*clientConn, 0, len(conns))
+	for cc := range conns {
+		targets = append(targets, cc)
+	}
+	ws.mu.RUnlock()
+	if len(targets) == 0 {
+		return
+	}
+	payload, err := json.Marshal(wsPush{ID: symbol, Data: data})
+	if err != nil {
+		log.Println("ws: failed to marshal push", err)
+		return
+	}
+	for _, cc := range targets {
+		select {
+		case cc.send <- payload:
+		default:
+			ws.unsubscribeAll(cc)
+			cc.close()
+		}
+	}
+}
+
+// handleConn services a single client connection until it errors or closes.
+func (ws *WebsocketServer) handleConn(conn *websocket.Conn) {
+	cc := newClientConn(atomic.AddUint64(&ws.nextID, 1), conn)
+	go cc.writePump()
+
+	conn.SetReadDeadline(time.Now().Add(clientPongWait))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(clientPongWait))
+		return nil
+	})
+
+	defer func() {
+		ws.unsubscribeAll(cc)
+		cc.close()
+	}()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		ws.handleRequest(cc, message)
+	}
+}
+
+// handleRequest dispatches a single JSON-RPC-style request from a client.
+func (ws *WebsocketServer) handleRequest(cc *clientConn, message []byte) {
+	var req wsRequest
+	if err := json.Unmarshal(message, &req); err != nil {
+		log.Println("ws: failed to unmarshal request", err)
+		return
+	}
+
+	switch req.Method {
+	case "subscribeTicker":
+		var params wsSubscribeParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			log.Println("ws: bad subscribeTicker params", err)
+			return
+		}
+		for _, symbol := range params.Symbols {
+			ws.subscribe(symbol, cc)
+			ws.ensureDynamicFeed(symbol)
+		}
+	case "unsubscribeTicker":
+		var params wsSubscribeParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			log.Println("ws: bad unsubscribeTicker params", err)
+			return
+		}
+		for _, symbol := range params.Symbols {
+			ws.unsubscribe(symbol, cc)
+		}
+	case "subscribeAll":
+		for _, symbol := range ws.symbols {
+			ws.subscribe(symbol, cc)
+		}
+	case "ping":
+		// handled by the ack below
+	default:
+		log.Printf("ws: unknown method %v", req.Method)
+		return
+	}
+
+	ack, err := json.Marshal(wsPush{ID: req.ID, Data: "ok"})
+	if err != nil {
+		return
+	}
+	select {
+	case cc.send <- ack:
+	default:
+		ws.unsubscribeAll(cc)
+		cc.close()
+	}
+}
+
+// serveWs upgrades the HTTP request to a websocket connection and hands it
+// off to the WebsocketServer for the life of the connection.
+func (ws *WebsocketServer) serveWs(w http.ResponseWriter, r *http.Request) {
+	conn, err := clientUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Println("ws: upgrade failed", err)
+		return
+	}
+	go ws.handleConn(conn)
+}
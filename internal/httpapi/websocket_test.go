@@ -0,0 +1,266 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/dchoweller/gows/internal/exchange"
+	"github.com/dchoweller/gows/internal/store"
+)
+
+// fakeDynamicExchange is a minimal exchange.Exchange that also implements
+// exchange.DynamicSubscriber, so tests can exercise /ws's dynamic-subscribe
+// wiring without a real upstream connection.
+type fakeDynamicExchange struct {
+	subscribed     chan string
+	subscribedCh   chan (chan<- exchange.Ticker)
+	unsubscribed   chan string
+	unsubscribedCh chan (chan<- exchange.Ticker)
+}
+
+func (f *fakeDynamicExchange) Name() string { return "fake" }
+func (f *fakeDynamicExchange) GetSymbol(pair exchange.CurrencyPair) (*exchange.SymbolInfo, error) {
+	return &exchange.SymbolInfo{}, nil
+}
+func (f *fakeDynamicExchange) GetCurrency(cur exchange.Currency) (*exchange.CurrencyInfo, error) {
+	return &exchange.CurrencyInfo{}, nil
+}
+func (f *fakeDynamicExchange) SubscribeTicker(pair exchange.CurrencyPair, ch chan<- exchange.Ticker) error {
+	return nil
+}
+func (f *fakeDynamicExchange) Subscribe(symbol string, ch chan<- exchange.Ticker) error {
+	f.subscribed <- symbol
+	if f.subscribedCh != nil {
+		f.subscribedCh <- ch
+	}
+	go func() { ch <- exchange.Ticker{Symbol: symbol, Last: "123"} }()
+	return nil
+}
+func (f *fakeDynamicExchange) Unsubscribe(symbol string, ch chan<- exchange.Ticker) {
+	f.unsubscribed <- symbol
+	if f.unsubscribedCh != nil {
+		f.unsubscribedCh <- ch
+	}
+}
+
+func dialTestServer(t *testing.T, srv *Server) (*websocket.Conn, func()) {
+	t.Helper()
+	ts := httptest.NewServer(srv.Router())
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		ts.Close()
+		t.Fatalf("dial /ws failed: %v", err)
+	}
+	return conn, func() {
+		conn.Close()
+		ts.Close()
+	}
+}
+
+func TestWebsocketSubscribeAndPublish(t *testing.T) {
+	st := store.New([]string{"BTCUSD"})
+	srv := NewServer(st, []string{"BTCUSD"}, nil, nil, nil)
+
+	conn, closeAll := dialTestServer(t, srv)
+	defer closeAll()
+
+	sub, _ := json.Marshal(wsRequest{
+		ID:     "1",
+		Method: "subscribeTicker",
+		Params: mustJSON(t, wsSubscribeParams{Symbols: []string{"BTCUSD"}}),
+	})
+	if err := conn.WriteMessage(websocket.TextMessage, sub); err != nil {
+		t.Fatalf("write subscribe request: %v", err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, _, err := conn.ReadMessage(); err != nil {
+		t.Fatalf("read subscribe ack: %v", err)
+	}
+
+	srv.Publish("BTCUSD", store.CurrencyInfo{Last: "50000"})
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, msg, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("read push: %v", err)
+	}
+	var push wsPush
+	if err := json.Unmarshal(msg, &push); err != nil {
+		t.Fatalf("unmarshal push: %v", err)
+	}
+	if push.ID != "BTCUSD" {
+		t.Errorf("push.ID = %q, want %q", push.ID, "BTCUSD")
+	}
+}
+
+func TestWebsocketUnsubscribeAndPing(t *testing.T) {
+	st := store.New([]string{"BTCUSD"})
+	srv := NewServer(st, []string{"BTCUSD"}, nil, nil, nil)
+
+	conn, closeAll := dialTestServer(t, srv)
+	defer closeAll()
+
+	requests := []wsRequest{
+		{ID: "1", Method: "subscribeTicker", Params: mustJSON(t, wsSubscribeParams{Symbols: []string{"BTCUSD"}})},
+		{ID: "2", Method: "unsubscribeTicker", Params: mustJSON(t, wsSubscribeParams{Symbols: []string{"BTCUSD"}})},
+		{ID: "3", Method: "ping"},
+	}
+	for _, req := range requests {
+		b, _ := json.Marshal(req)
+		if err := conn.WriteMessage(websocket.TextMessage, b); err != nil {
+			t.Fatalf("write %v request: %v", req.Method, err)
+		}
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("read %v ack: %v", req.Method, err)
+		}
+		var ack wsPush
+		if err := json.Unmarshal(msg, &ack); err != nil {
+			t.Fatalf("unmarshal %v ack: %v", req.Method, err)
+		}
+		if ack.ID != req.ID {
+			t.Errorf("%v ack.ID = %q, want %q", req.Method, ack.ID, req.ID)
+		}
+	}
+
+	// Publishing after unsubscribe should reach no one; the server-side
+	// subs map for the symbol should now be empty.
+	srv.ws.publish("BTCUSD", store.CurrencyInfo{Last: "1"})
+}
+
+func TestWebsocketUnknownMethod(t *testing.T) {
+	st := store.New([]string{"BTCUSD"})
+	srv := NewServer(st, []string{"BTCUSD"}, nil, nil, nil)
+
+	conn, closeAll := dialTestServer(t, srv)
+	defer closeAll()
+
+	b, _ := json.Marshal(wsRequest{ID: "1", Method: "bogus"})
+	if err := conn.WriteMessage(websocket.TextMessage, b); err != nil {
+		t.Fatalf("write request: %v", err)
+	}
+
+	// An unknown method gets no ack, so the next read times out.
+	conn.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+	if _, _, err := conn.ReadMessage(); err == nil {
+		t.Error("expected no response to an unknown method")
+	}
+}
+
+func TestWebsocketSubscribeDynamicSymbol(t *testing.T) {
+	st := store.New([]string{"BTCUSD"})
+	ex := &fakeDynamicExchange{subscribed: make(chan string, 1), unsubscribed: make(chan string, 1)}
+	srv := NewServer(st, []string{"BTCUSD"}, ex, nil, nil)
+
+	conn, closeAll := dialTestServer(t, srv)
+	defer closeAll()
+
+	sub, _ := json.Marshal(wsRequest{
+		ID:     "1",
+		Method: "subscribeTicker",
+		Params: mustJSON(t, wsSubscribeParams{Symbols: []string{"ETHUSD"}}),
+	})
+	if err := conn.WriteMessage(websocket.TextMessage, sub); err != nil {
+		t.Fatalf("write subscribe request: %v", err)
+	}
+
+	select {
+	case symbol := <-ex.subscribed:
+		if symbol != "ETHUSD" {
+			t.Errorf("subscribed upstream to %q, want %q", symbol, "ETHUSD")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("exchange.Subscribe was never called for the dynamic symbol")
+	}
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, _, err := conn.ReadMessage(); err != nil {
+		t.Fatalf("read subscribe ack: %v", err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, msg, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("read push from the dynamically subscribed symbol: %v", err)
+	}
+	var push wsPush
+	if err := json.Unmarshal(msg, &push); err != nil {
+		t.Fatalf("unmarshal push: %v", err)
+	}
+	if push.ID != "ETHUSD" {
+		t.Errorf("push.ID = %q, want %q", push.ID, "ETHUSD")
+	}
+
+	unsub, _ := json.Marshal(wsRequest{
+		ID:     "2",
+		Method: "unsubscribeTicker",
+		Params: mustJSON(t, wsSubscribeParams{Symbols: []string{"ETHUSD"}}),
+	})
+	if err := conn.WriteMessage(websocket.TextMessage, unsub); err != nil {
+		t.Fatalf("write unsubscribe request: %v", err)
+	}
+
+	select {
+	case symbol := <-ex.unsubscribed:
+		if symbol != "ETHUSD" {
+			t.Errorf("unsubscribed upstream from %q, want %q", symbol, "ETHUSD")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("exchange.Unsubscribe was never called after the last client unsubscribed")
+	}
+}
+
+func TestWebsocketResubscribeDynamicSymbolAfterUnsubscribe(t *testing.T) {
+	st := store.New([]string{"BTCUSD"})
+	ex := &fakeDynamicExchange{
+		subscribed:     make(chan string, 2),
+		subscribedCh:   make(chan (chan<- exchange.Ticker), 2),
+		unsubscribed:   make(chan string, 1),
+		unsubscribedCh: make(chan (chan<- exchange.Ticker), 1),
+	}
+	srv := NewServer(st, []string{"BTCUSD"}, ex, nil, nil)
+
+	srv.ws.ensureDynamicFeed("ETHUSD")
+	firstCh := <-ex.subscribedCh
+	<-ex.subscribed
+
+	srv.ws.stopDynamicFeed("ETHUSD")
+	select {
+	case gotCh := <-ex.unsubscribedCh:
+		if gotCh != firstCh {
+			t.Error("Unsubscribe was called with a different channel than the one Subscribe registered")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("exchange.Unsubscribe was never called")
+	}
+
+	// Resubscribing to the same symbol after it was fully unsubscribed
+	// should start a brand new feed, not silently no-op because a stale
+	// entry is still sitting in ws.dynFeeds.
+	srv.ws.ensureDynamicFeed("ETHUSD")
+	select {
+	case secondCh := <-ex.subscribedCh:
+		if secondCh == firstCh {
+			t.Error("resubscribe reused the old channel instead of starting a fresh feed")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("exchange.Subscribe was never called again after resubscribing")
+	}
+}
+
+func mustJSON(t *testing.T, v interface{}) json.RawMessage {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return b
+}
@@ -0,0 +1,125 @@
+// Package store holds the latest known price/metadata for every symbol this
+// service tracks, behind a Store interface so HTTP handlers can be tested
+// against a fake instead of depending on a concrete, global implementation.
+package store
+
+import (
+	"sync"
+	"time"
+)
+
+// CurrencyInfo is the latest known state for a single tracked symbol.
+type CurrencyInfo struct {
+	ID          string    `json:"id"`
+	FullName    string    `json:"fullName"`
+	Ask         string    `json:"ask"`
+	Bid         string    `json:"bid"`
+	Last        string    `json:"last"`
+	Open        string    `json:"open"`
+	Low         string    `json:"low"`
+	High        string    `json:"high"`
+	FeeCurrency string    `json:"feeCurrency"`
+	Exchange    string    `json:"exchange"`
+	Source      string    `json:"source"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// TickerUpdate is a price tick for Symbol, tagged with the name of the
+// source that produced it (an exchange name or a fallback PriceSource's
+// name).
+type TickerUpdate struct {
+	Symbol string
+	Ask    string
+	Bid    string
+	Last   string
+	Open   string
+	Low    string
+	High   string
+	Source string
+}
+
+// Store is the interface httpapi handlers depend on.
+type Store interface {
+	// Get returns the cached info for symbol, and whether it is tracked.
+	Get(symbol string) (CurrencyInfo, bool)
+	// GetAll returns cached info for every tracked symbol.
+	GetAll() []CurrencyInfo
+	// Seed sets symbol's static metadata (id, full name, exchange),
+	// fetched once at startup. It leaves any previously applied ticker
+	// fields untouched.
+	Seed(symbol string, info CurrencyInfo)
+	// Update applies a price tick, tagging it with its source and the
+	// current time.
+	Update(u TickerUpdate)
+}
+
+// memoryStore is an in-memory Store, with one lock per symbol so a slow
+// reader of one symbol never blocks an update to another.
+type memoryStore struct {
+	locks   []sync.Mutex
+	records []CurrencyInfo
+	index   map[string]int
+}
+
+// New builds a Store tracking exactly the given symbols.
+func New(symbols []string) Store {
+	s := &memoryStore{
+		locks:   make([]sync.Mutex, len(symbols)),
+		records: make([]CurrencyInfo, len(symbols)),
+		index:   make(map[string]int, len(symbols)),
+	}
+	for i, symbol := range symbols {
+		s.index[symbol] = i
+	}
+	return s
+}
+
+func (s *memoryStore) Get(symbol string) (CurrencyInfo, bool) {
+	i, ok := s.index[symbol]
+	if !ok {
+		return CurrencyInfo{}, false
+	}
+	s.locks[i].Lock()
+	defer s.locks[i].Unlock()
+	return s.records[i], true
+}
+
+func (s *memoryStore) GetAll() []CurrencyInfo {
+	result := make([]CurrencyInfo, len(s.records))
+	for i := range s.records {
+		s.locks[i].Lock()
+		result[i] = s.records[i]
+		s.locks[i].Unlock()
+	}
+	return result
+}
+
+func (s *memoryStore) Seed(symbol string, info CurrencyInfo) {
+	i, ok := s.index[symbol]
+	if !ok {
+		return
+	}
+	s.locks[i].Lock()
+	defer s.locks[i].Unlock()
+	s.records[i].ID = info.ID
+	s.records[i].FullName = info.FullName
+	s.records[i].FeeCurrency = info.FeeCurrency
+	s.records[i].Exchange = info.Exchange
+}
+
+func (s *memoryStore) Update(u TickerUpdate) {
+	i, ok := s.index[u.Symbol]
+	if !ok {
+		return
+	}
+	s.locks[i].Lock()
+	defer s.locks[i].Unlock()
+	s.records[i].Ask = u.Ask
+	s.records[i].Bid = u.Bid
+	s.records[i].Last = u.Last
+	s.records[i].Open = u.Open
+	s.records[i].Low = u.Low
+	s.records[i].High = u.High
+	s.records[i].Source = u.Source
+	s.records[i].UpdatedAt = time.Now()
+}
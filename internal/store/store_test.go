@@ -0,0 +1,48 @@
+package store
+
+import "testing"
+
+func TestGetUnknownSymbol(t *testing.T) {
+	s := New([]string{"BTCUSD"})
+	if _, ok := s.Get("ETHBTC"); ok {
+		t.Fatal("Get returned ok for an untracked symbol")
+	}
+}
+
+func TestSeedThenUpdate(t *testing.T) {
+	s := New([]string{"BTCUSD"})
+	s.Seed("BTCUSD", CurrencyInfo{ID: "BTC", FullName: "Bitcoin", Exchange: "hitbtc"})
+	s.Update(TickerUpdate{Symbol: "BTCUSD", Last: "50000", Source: "hitbtc"})
+
+	info, ok := s.Get("BTCUSD")
+	if !ok {
+		t.Fatal("Get returned !ok for a tracked symbol")
+	}
+	if info.ID != "BTC" || info.FullName != "Bitcoin" {
+		t.Errorf("Update clobbered metadata set by Seed: %+v", info)
+	}
+	if info.Last != "50000" || info.Source != "hitbtc" {
+		t.Errorf("Update did not apply ticker fields: %+v", info)
+	}
+	if info.UpdatedAt.IsZero() {
+		t.Error("Update did not stamp UpdatedAt")
+	}
+}
+
+func TestUpdateUnknownSymbolIsIgnored(t *testing.T) {
+	s := New([]string{"BTCUSD"})
+	s.Update(TickerUpdate{Symbol: "ETHBTC", Last: "1"})
+	if _, ok := s.Get("ETHBTC"); ok {
+		t.Fatal("Update grew the store with an untracked symbol")
+	}
+}
+
+func TestGetAll(t *testing.T) {
+	s := New([]string{"BTCUSD", "ETHBTC"})
+	s.Update(TickerUpdate{Symbol: "BTCUSD", Last: "50000"})
+
+	all := s.GetAll()
+	if len(all) != 2 {
+		t.Fatalf("GetAll returned %d records, want 2", len(all))
+	}
+}